@@ -17,7 +17,7 @@ function setLeaderboard() {
                 }
                 leaderboardStat.innerHTML = `
                     ${hatHtml}
-                    <img src="${user.avatar}" class="leaderboard-profile">
+                    <img src="${user.avatar || '/git.svg'}" class="leaderboard-profile">
                     <div class="leaderboard-inside">
                         <h1>#${rank}</h1>
                     </div>
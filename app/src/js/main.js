@@ -44,16 +44,6 @@ function startLiveTimestamps() {
     startLiveTimestamps._t = setTimeout(startLiveTimestamps, next);
 }
 
-function repoDisplayName(apiUrl) {
-    try {
-        const path = apiUrl.replace('https://api.github.com/repos/', '');
-        const [owner, repo] = path.split('/');
-        return { displayName: `${owner}/${repo}`, publicUrl: `https://github.com/${owner}/${repo}` };
-    } catch {
-        return { displayName: apiUrl, publicUrl: '#' };
-    }
-}
-
 function animateCounter(el, target, duration) {
     const step = target / (duration / 16);
     let current = 0;
@@ -66,14 +56,15 @@ function animateCounter(el, target, duration) {
 }
 
 function createCard(leak) {
-    const { displayName, publicUrl } = repoDisplayName(leak.repo_name);
-    const fileUrl = `${publicUrl}/blob/main/${leak.file_path}`;
+    const displayName = leak.repo_display_name || leak.repo_name;
+    const publicUrl = leak.repo_url || '#';
+    const fileUrl = leak.repo_url ? fileLink(leak) : '#';
     const card = document.createElement('article');
     card.className = 'card';
     card.innerHTML = `
         <div class="card-header">
             <pre class="card-key"><code>${leak.key}</code></pre>
-            <span class="card-provider-badge ${leak.provider}">${leak.provider}</span>
+            <span class="card-provider-badge ${leak.provider}"${leak.confidence === 'low' ? ' title="Low confidence: found by the name it was assigned to"' : ''}>${leak.provider}${leak.confidence === 'low' ? '?' : ''}</span>
         </div>
         <div class="card-body">
             <div class="card-row card-repo">
@@ -97,6 +88,21 @@ function createCard(leak) {
     return card;
 }
 
+function fileLink(leak) {
+    const ref = leak.commit_sha || leak.branch || 'HEAD';
+    const line = leak.line ? `#L${leak.line}` : '';
+    switch (leak.repo_source) {
+    case 'gitea': // has to be told whether the ref is a commit or a branch
+        if (leak.commit_sha) return `${leak.repo_url}/src/commit/${leak.commit_sha}/${leak.file_path}${line}`;
+        if (leak.branch) return `${leak.repo_url}/src/branch/${leak.branch}/${leak.file_path}${line}`;
+        return leak.repo_url;
+    case 'gitlab':
+        return `${leak.repo_url}/-/blob/${ref}/${leak.file_path}${line}`;
+    default:
+        return `${leak.repo_url}/blob/${ref}/${leak.file_path}${line}`;
+    }
+}
+
 function clearMessages() {
     document.querySelectorAll('.loading-message, .end-message, .empty-message').forEach(el => el.remove());
 }
@@ -199,6 +205,14 @@ async function pollForNewLeaks() {
     } catch { }
 }
 
+function addLiveFinding(leak) {
+    const filter = currentFilter.toLowerCase();
+    if (filter !== 'all' && filter !== leak.provider) return;
+    if (allLeaks.some(l => l.id === leak.id)) return;
+    allLeaks.unshift(leak);
+    appendCards([leak], true);
+}
+
 function restartPolling() {
     clearInterval(pollingIntervalId);
     pollingIntervalId = setInterval(pollForNewLeaks, 15000);
@@ -215,7 +229,7 @@ function setupTabFiltering() {
         currentPage = 1;
         tabs.querySelector('.active')?.classList.remove('active');
         tab.classList.add('active');
-        currentFilter = tab.textContent;
+        currentFilter = tab.dataset.provider || tab.textContent;
         fetchLeaks(currentPage, currentFilter);
         restartPolling();
     });
@@ -248,20 +262,19 @@ function runTicker() {
     ticker.addEventListener('animationend', runTicker, { once: true });
 }
 
-function addTickerItem(url) {
-    try {
-        const parts = url.replace('https://api.github.com/repos/', '').split('/');
-        tickerQueue.push(`${parts[0]}/${parts[1]}`);
-    } catch {
-        tickerQueue.push(url);
-    }
+function addTickerItem(repo) {
+    tickerQueue.push(repo.name || repo.url);
     if (!tickerBusy) runTicker();
 }
 
 function connectWebSocket() {
     const ws = new WebSocket(`wss://${location.host}/ws/live`);
     ws.onmessage = e => {
-        try { addTickerItem(JSON.parse(e.data).url); } catch { }
+        try {
+            const msg = JSON.parse(e.data);
+            if (msg.type === 'finding') addLiveFinding(msg.finding);
+            else addTickerItem(msg);
+        } catch { }
     };
     ws.onclose = () => setTimeout(connectWebSocket, 3000);
     ws.onerror = () => ws.close();
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"openradar/internal/cli"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "scan" {
+		fmt.Fprint(os.Stderr, cli.Usage)
+		os.Exit(cli.ExitError)
+	}
+	os.Exit(cli.Scan(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+}
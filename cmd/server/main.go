@@ -9,9 +9,15 @@ import (
 
 	"openradar/internal/config"
 	"openradar/internal/db"
+	"openradar/internal/github"
 	"openradar/internal/jobs"
 	"openradar/internal/queue"
+	"openradar/internal/scanner/detectors"
+	"openradar/internal/scanner/filters"
+	"openradar/internal/scanner/rules"
 	"openradar/internal/server"
+	"openradar/internal/sources"
+	"openradar/internal/verifier"
 	"openradar/internal/worker"
 )
 
@@ -21,23 +27,96 @@ func main() {
 
 	cfg := config.Load()
 
-	queue.NewInMemoryQueue(100)
+	if cfg.Scanner.RulesFile != "" {
+		count, err := rules.LoadAndRegister(cfg.Scanner.RulesFile)
+		if err != nil {
+			log.Fatalf("rules init failed: %v", err)
+		}
+		log.Printf("loaded %d rules from %s", count, cfg.Scanner.RulesFile)
+	}
+
+	if cfg.Scanner.Generic {
+		keywords := cfg.Scanner.GenericKeywords
+		if len(keywords) == 0 {
+			keywords = detectors.DefaultGenericKeywords
+		}
+		if err := detectors.Generic.Configure(keywords, cfg.Scanner.GenericMinEntropy); err != nil {
+			log.Fatalf("generic detector init failed: %v", err)
+		}
+	} else {
+		detectors.Unregister(detectors.Generic.Provider())
+	}
+
+	if cfg.Scanner.FiltersFile != "" {
+		pipeline, err := filters.LoadFile(cfg.Scanner.FiltersFile)
+		if err != nil {
+			log.Fatalf("filters init failed: %v", err)
+		}
+		filters.Configure(pipeline)
+	}
+
+	verifier.Configure(cfg.Verifier.BaseURLs)
 
 	database, err := db.New(cfg.Database.URL)
 	if err != nil {
 		log.Fatalf("database init failed: %v", err)
 	}
 
-	hub := server.StartServer(database, cfg) // websocket
+	retryPolicy := queue.RetryPolicy{
+		MaxAttempts: cfg.Queue.MaxAttempts,
+		BaseDelay:   cfg.Queue.RetryBase,
+		MaxDelay:    cfg.Queue.RetryMax,
+	}
+
+	var jobQueue queue.Queue
+	if cfg.Queue.Backend == "memory" {
+		jobQueue = queue.NewInMemoryQueue(cfg.Queue.Size, cfg.Queue.VisibilityTimeout, retryPolicy)
+	} else {
+		pgQueue, err := queue.NewPostgresQueue(database, cfg.Queue.VisibilityTimeout, retryPolicy)
+		if err != nil {
+			log.Fatalf("queue init failed: %v", err)
+		}
+		jobQueue = pgQueue
+	}
+
+	tokens := github.NewPool()
+	for _, token := range cfg.GitHub.Tokens {
+		tokens.Add(github.StaticToken(token))
+	}
+	if cfg.GitHub.AppID != "" {
+		privateKey, err := os.ReadFile(cfg.GitHub.AppPrivateKeyFile)
+		if err != nil {
+			log.Fatalf("github app init failed: %v", err)
+		}
+		for _, installationID := range cfg.GitHub.AppInstallationIDs {
+			installation, err := github.NewAppInstallation(cfg.GitHub.APIURL, cfg.GitHub.AppID, installationID, privateKey)
+			if err != nil {
+				log.Fatalf("github app init failed: %v", err)
+			}
+			tokens.Add(installation)
+		}
+	}
+	githubClient := github.NewClient(cfg.GitHub.APIURL, tokens)
+
+	sources.Register(sources.NewGitHub(githubClient, cfg.GitHub.WebURL, cfg.GitHub.CloneHost))
+	if cfg.GitLab.URL != "" {
+		sources.Register(sources.NewGitLab(cfg.GitLab.URL, cfg.GitLab.Token))
+	}
+	if cfg.Gitea.URL != "" {
+		sources.Register(sources.NewGitea(cfg.Gitea.URL, cfg.Gitea.Token))
+	}
+
+	hub := server.StartServer(database, cfg, jobQueue, githubClient) // websocket
 
 	for i := 0; i < cfg.Scanner.MaxConcurrentClones; i++ {
-		worker.Start(ctx, cfg, database, hub)
+		worker.Start(ctx, cfg, database, hub, jobQueue)
 	}
 
 	jobContext := jobs.JobContext{
-		DB:  database,
-		Cfg: cfg,
-		Ctx: ctx,
+		DB:    database,
+		Cfg:   cfg,
+		Ctx:   ctx,
+		Queue: jobQueue,
 	}
 
 	jobs.RunJobs(jobContext)
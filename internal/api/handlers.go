@@ -1,31 +1,43 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
+	"openradar/internal/db"
 	"openradar/internal/db/cache"
 	"openradar/internal/domain"
+	"openradar/internal/queue"
+	"openradar/internal/scanner/detectors"
+	"openradar/internal/scanner/filters"
+	"openradar/internal/sources"
+	"slices"
 
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// ErrInvalidQuery means the caller asked for something that can't be
+// answered, like an unknown provider, rather than the lookup failing.
+var ErrInvalidQuery = errors.New("invalid query")
+
 func GetLatestFindings(page int, pageSize int, provider string, minAge string, dbToGrabFrom *gorm.DB) (*domain.PaginatedFindings, error) {
 	if page < 1 {
-		return nil, fmt.Errorf("page must be greater than 0")
+		return nil, fmt.Errorf("%w: page must be greater than 0", ErrInvalidQuery)
 	}
 	if pageSize < 1 || pageSize > 100 {
-		return nil, fmt.Errorf("page_size must be between 1 and 100")
+		return nil, fmt.Errorf("%w: page_size must be between 1 and 100", ErrInvalidQuery)
 	}
 
 	duration, err := time.ParseDuration(minAge)
 	if err != nil {
-		return nil, fmt.Errorf("invalid minAge format")
+		return nil, fmt.Errorf("%w: invalid minAge format", ErrInvalidQuery)
 	}
 
 	if duration < 0 || duration > 365*24*time.Hour {
-		return nil, fmt.Errorf("minAge must be between 0 and 1 year")
+		return nil, fmt.Errorf("%w: minAge must be between 0 and 1 year", ErrInvalidQuery)
 	}
 
 	cutOffTime := time.Now().Add(-duration)
@@ -36,20 +48,10 @@ func GetLatestFindings(page int, pageSize int, provider string, minAge string, d
 	query := dbToGrabFrom.Model(&domain.Finding{}).Where("detected_at >= ?", cutOffTime)
 
 	if provider != "*" {
-		validProviders := map[string]bool{
-			"anthropic":  true,
-			"cerebras":   true,
-			"google":     true,
-			"groq":       true,
-			"mistral":    true,
-			"openai":     true,
-			"openrouter": true,
-			"xai":        true,
-			"slack":      true,
-			"discord":    true,
-		}
-		if !validProviders[provider] {
-			return nil, fmt.Errorf("invalid provider: %s", provider)
+		// every registered detector's provider, including custom rules, and
+		// generic, which has findings from before SCAN_GENERIC was turned off
+		if !slices.Contains(detectors.Providers(), provider) && provider != detectors.Generic.Provider() {
+			return nil, fmt.Errorf("%w: unknown provider %s", ErrInvalidQuery, provider)
 		}
 		query = query.Where("provider = ?", provider)
 	}
@@ -70,6 +72,10 @@ func GetLatestFindings(page int, pageSize int, provider string, minAge string, d
 		return nil, fmt.Errorf("error fetching findings: %w", result.Error)
 	}
 
+	for i := range findings {
+		sources.SetDisplayURL(&findings[i])
+	}
+
 	totalPages := int(math.Ceil(float64(totalCount) / float64(pageSize)))
 
 	return &domain.PaginatedFindings{
@@ -130,6 +136,10 @@ func GetFindingsFromRepository(repo_url string, page int, pageSize int, dbToGrab
 		return nil, fmt.Errorf("error fetching findings: %w", result.Error)
 	}
 
+	for i := range findings {
+		sources.SetDisplayURL(&findings[i])
+	}
+
 	totalPages := int(math.Ceil(float64(totalCount) / float64(pageSize)))
 
 	return &domain.PaginatedFindings{
@@ -179,12 +189,138 @@ func GetAllRepositories(page int, pageSize int, dbToGrabFrom *gorm.DB) (*domain.
 	}, nil
 }
 
+func GetScanJobs(page int, pageSize int, status string, dbToGrabFrom *gorm.DB) (*domain.PaginatedScanJobs, error) {
+	if page < 1 {
+		return nil, fmt.Errorf("page must be greater than 0")
+	}
+	if pageSize < 1 || pageSize > 100 {
+		return nil, fmt.Errorf("page_size must be between 1 and 100")
+	}
+
+	var jobs []domain.ScanJob
+	var totalCount int64
+
+	query := dbToGrabFrom.Model(&domain.ScanJob{})
+
+	if status != "*" {
+		validStatuses := map[domain.ScanJobStatus]bool{
+			domain.JobStatusPending:    true,
+			domain.JobStatusInProgress: true,
+			domain.JobStatusCompleted:  true,
+			domain.JobStatusFailed:     true,
+			domain.JobStatusDead:       true,
+		}
+		if !validStatuses[domain.ScanJobStatus(status)] {
+			return nil, fmt.Errorf("invalid status: %s", status)
+		}
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, fmt.Errorf("error counting scan jobs: %w", err)
+	}
+
+	offset := (page - 1) * pageSize
+
+	result := query.
+		Order("created_at DESC").
+		Limit(pageSize).
+		Offset(offset).
+		Find(&jobs)
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("error fetching scan jobs: %w", result.Error)
+	}
+
+	totalPages := int(math.Ceil(float64(totalCount) / float64(pageSize)))
+
+	return &domain.PaginatedScanJobs{
+		Jobs:       jobs,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: totalCount,
+		TotalPages: totalPages,
+	}, nil
+}
+
+func GetScanJob(id string, dbToGrabFrom *gorm.DB) (*domain.ScanJobDetails, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	job, err := db.GetScanJob(id, dbToGrabFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	transitions, err := db.GetScanJobTransitions(id, dbToGrabFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ScanJobDetails{
+		ScanJob:     *job,
+		Transitions: transitions,
+	}, nil
+}
+
+// RequeueScanJob gives a dead job a fresh set of attempts.
+func RequeueScanJob(ctx context.Context, id string, jobQueue queue.Queue, dbToSaveIn *gorm.DB) (*domain.ScanJob, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	job, err := jobQueue.Requeue(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.SaveScanJob(job, dbToSaveIn, job.Transition(domain.JobStatusDead)); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// RequeueDeadScanJobs requeues every dead job, skipping (and not counting)
+// those whose repository has been queued again since.
+func RequeueDeadScanJobs(ctx context.Context, jobQueue queue.Queue, dbToSaveIn *gorm.DB) (int, error) {
+	ids, err := jobQueue.DeadJobIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	requeued := 0
+	for _, id := range ids {
+		_, err := RequeueScanJob(ctx, id, jobQueue, dbToSaveIn)
+		if errors.Is(err, queue.ErrJobActive) || errors.Is(err, queue.ErrJobNotDead) {
+			continue
+		}
+		if err != nil {
+			return requeued, err
+		}
+		requeued++
+	}
+	return requeued, nil
+}
+
+func GetEventCursors(dbToGrabFrom *gorm.DB) ([]domain.EventCursor, error) {
+	return db.GetEventCursors(dbToGrabFrom)
+}
+
 // These are cached by jobs.
 
 func GetLeaderboardData() []domain.LeaderboardEntry {
 	return cache.GetCachedLeaderboard()
 }
 
+func GetRevocationMetrics() domain.RevocationMetrics {
+	return cache.GetCachedRevocationMetrics()
+}
+
+func GetFilterMetrics() map[string]int64 {
+	return filters.Counts()
+}
+
 func GetFindingsCount() int64 {
 	return cache.FindingsCount
 }
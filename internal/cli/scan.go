@@ -0,0 +1,204 @@
+// The openradar command line, kept out of package main so the tests can run
+// it.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"openradar/internal/scanner/detectors"
+	"openradar/internal/scanner/filters"
+	"openradar/internal/scanner/local"
+	"openradar/internal/scanner/report"
+	"openradar/internal/scanner/rules"
+)
+
+// Exit codes, so CI can tell a leak from a broken scan.
+const (
+	ExitClean    = 0
+	ExitFindings = 1
+	ExitError    = 2
+)
+
+const Usage = `usage: openradar scan [flags] <path>
+
+Scans a directory, a git repository's history (-history) or stdin (path -)
+for secrets. Exits 1 if any are found, 2 if the scan failed. Lines with an
+openradar:allow comment, and findings recorded in the -baseline file, are
+reported as suppressed and don't count.
+
+flags:
+`
+
+// Scan runs openradar scan with args, the arguments after "scan", and
+// returns the exit code.
+func Scan(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("scan", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, Usage)
+		flags.PrintDefaults()
+	}
+
+	format := flags.String("format", report.Text, "output format: "+strings.Join(report.Formats, ", "))
+	output := flags.String("output", "", "write findings to this file instead of stdout")
+	scanHistory := flags.Bool("history", false, "scan the lines added by every commit instead of the files on disk")
+	commits := flags.Int("commits", 0, "with -history, only the last n commits")
+	days := flags.Int("days", 0, "with -history, only commits from the last n days")
+	maxFileKB := flags.Int("max-file-kb", 2048, "skip files larger than this")
+	rulesFile := flags.String("rules", "", "extra detector rules (gitleaks-style TOML)")
+	generic := flags.Bool("generic", false, "also look for high-entropy values assigned to names like password or api_key")
+	genericKeywords := flags.String("generic-keywords", "", "comma separated names for -generic (default "+strings.Join(detectors.DefaultGenericKeywords, ",")+")")
+	genericMinEntropy := flags.Float64("generic-min-entropy", detectors.DefaultGenericMinEntropy, "bits per character a -generic value needs")
+	filtersFile := flags.String("filters", "", "false-positive filter settings (TOML)")
+	debugFilters := flags.Bool("debug-filters", false, "print every match the filters drop, and why, to stderr")
+	showSecrets := flags.Bool("show-secrets", false, "print keys in full instead of redacted")
+	baselineFile := flags.String("baseline", "", "ignore the findings recorded in this file (create it with -update-baseline)")
+	updateBaseline := flags.Bool("update-baseline", false, "write the -baseline file from the current findings")
+
+	// Allow flags after the path too, as in `openradar scan . -format json`.
+	var paths []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return ExitError
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		paths = append(paths, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if len(paths) > 1 {
+		flags.Usage()
+		return ExitError
+	}
+	path := "."
+	if len(paths) == 1 {
+		path = paths[0]
+	}
+
+	if *updateBaseline && *baselineFile == "" {
+		fmt.Fprintln(stderr, "-update-baseline needs -baseline")
+		return ExitError
+	}
+
+	if *rulesFile != "" {
+		if _, err := rules.LoadAndRegister(*rulesFile); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitError
+		}
+	}
+
+	if *generic {
+		keywords := detectors.DefaultGenericKeywords
+		if *genericKeywords != "" {
+			keywords = strings.Split(*genericKeywords, ",")
+		}
+		if err := detectors.Generic.Configure(keywords, *genericMinEntropy); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitError
+		}
+	} else {
+		detectors.Unregister(detectors.Generic.Provider())
+	}
+
+	if *filtersFile != "" {
+		pipeline, err := filters.LoadFile(*filtersFile)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitError
+		}
+		filters.Configure(pipeline)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts := local.Options{
+		MaxFileSize: int64(*maxFileKB) * 1024,
+		MaxCommits:  *commits,
+	}
+	if *days > 0 {
+		opts.Since = time.Now().AddDate(0, 0, -*days)
+	}
+	if *debugFilters {
+		opts.OnReject = func(finding local.Finding, rejection filters.Rejection) {
+			fmt.Fprintf(stderr, "filtered %s:%d:%d: %s key %s: %s (%s)\n", finding.Path, finding.Line, finding.Column,
+				finding.Provider, detectors.Redact(finding.Key), rejection.Reason, rejection.Detail)
+		}
+	}
+
+	// A missing baseline is an error rather than an empty one, so a typo in
+	// CI doesn't quietly record (and pass) whatever this run finds.
+	recordBaseline := *updateBaseline
+	if *baselineFile != "" && !recordBaseline {
+		baseline, err := detectors.LoadBaseline(*baselineFile)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			fmt.Fprintf(stderr, "baseline %s doesn't exist; create it with -update-baseline\n", *baselineFile)
+			return ExitError
+		case err != nil:
+			fmt.Fprintln(stderr, err)
+			return ExitError
+		default:
+			opts.Baseline = baseline
+		}
+	}
+
+	var findings []local.Finding
+	var err error
+	switch {
+	case path == "-":
+		findings, err = local.ScanReader(stdin, "stdin", opts)
+	case *scanHistory:
+		findings, err = local.ScanHistory(ctx, path, opts)
+	default:
+		findings, err = local.ScanDir(ctx, path, opts)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+
+	if recordBaseline {
+		baseline := detectors.NewBaseline()
+		for _, finding := range local.Unsuppressed(findings) {
+			baseline.Add(finding.Provider, finding.Path, finding.Key, finding.Line)
+		}
+		if err := baseline.Save(*baselineFile); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitError
+		}
+		fmt.Fprintf(stderr, "recorded %d finding(s) in %s\n", baseline.Len(), *baselineFile)
+		return ExitClean
+	}
+
+	out := stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitError
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := report.Write(out, *format, findings, report.Options{ShowSecrets: *showSecrets}); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+
+	if len(local.Unsuppressed(findings)) > 0 {
+		return ExitFindings
+	}
+	return ExitClean
+}
@@ -4,11 +4,20 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Scanner.HistoryMode values
+const (
+	HistoryHead    = "head"    // only the latest commit's files
+	HistoryFull    = "full"    // every commit on the default branch
+	HistoryCommits = "commits" // the last HistoryDepth commits
+	HistoryDays    = "days"    // commits from the last HistoryDepth days
+)
+
 type Config struct {
 	Env string
 
@@ -21,13 +30,65 @@ type Config struct {
 	}
 
 	GitHub struct {
-		Key string
+		// Point these at a GitHub Enterprise Server to watch it instead
+		APIURL    string
+		WebURL    string
+		CloneHost string // defaults to whatever host the API's clone URLs use
+
+		Tokens []string
+
+		// GitHub App auth, used alongside or instead of Tokens
+		AppID              string
+		AppInstallationIDs []string
+		AppPrivateKeyFile  string
+	}
+
+	// Extra sources, off unless a URL is set
+	GitLab struct {
+		URL   string
+		Token string
+	}
+
+	Gitea struct {
+		URL   string
+		Token string
+	}
+
+	Admin struct {
+		Token string // admin endpoints are disabled when empty
 	}
 
 	Scanner struct {
 		MaxRepoSizeMB       int
 		MaxFileSizeKB       int
 		MaxConcurrentClones int
+		RulesFile           string
+		FiltersFile         string
+		FilterDebug         bool // log every match the filters drop, and why
+		Generic             bool // look for keyword = "high entropy value" assignments
+		GenericKeywords     []string
+		GenericMinEntropy   float64
+		HistoryMode         string
+		HistoryDepth        int
+		PushDiff            bool
+		CloneTimeout        time.Duration
+	}
+
+	Queue struct {
+		Backend           string // postgres or memory
+		Size              int    // memory only
+		VisibilityTimeout time.Duration
+		MaxAttempts       int
+		RetryBase         time.Duration
+		RetryMax          time.Duration
+	}
+
+	Verifier struct {
+		Enabled     bool
+		Timeout     time.Duration
+		BaseURLs    map[string]string
+		RecheckBase time.Duration
+		RecheckMax  time.Duration
 	}
 }
 
@@ -46,8 +107,47 @@ func Load() Config {
 	cfg.Scanner.MaxRepoSizeMB = mustInt(getEnv("SCAN_MAX_REPO_MB", "50"))
 	cfg.Scanner.MaxFileSizeKB = mustInt(getEnv("SCAN_MAX_FILE_KB", "2048"))
 	cfg.Scanner.MaxConcurrentClones = mustInt(getEnv("SCAN_MAX_CONCURRENT", "5"))
+	cfg.Scanner.RulesFile = getEnv("SCAN_RULES_FILE", "")
+	cfg.Scanner.FiltersFile = getEnv("SCAN_FILTERS_FILE", "")
+	cfg.Scanner.FilterDebug = mustBool(getEnv("SCAN_FILTER_DEBUG", "false"))
+	cfg.Scanner.Generic = mustBool(getEnv("SCAN_GENERIC", "false"))
+	cfg.Scanner.GenericKeywords = mustList(getEnv("SCAN_GENERIC_KEYWORDS", ""))
+	cfg.Scanner.GenericMinEntropy = mustFloat(getEnv("SCAN_GENERIC_MIN_ENTROPY", "3.5"))
+	cfg.Scanner.HistoryMode = getEnv("SCAN_HISTORY_MODE", HistoryHead)
+	cfg.Scanner.HistoryDepth = mustInt(getEnv("SCAN_HISTORY_DEPTH", "50"))
+	cfg.Scanner.PushDiff = mustBool(getEnv("SCAN_PUSH_DIFF", "false"))
+	cfg.Scanner.CloneTimeout = mustDuration(getEnv("SCAN_CLONE_TIMEOUT", "60s"))
+
+	cfg.Queue.Backend = getEnv("QUEUE_BACKEND", "postgres")
+	cfg.Queue.Size = mustInt(getEnv("QUEUE_SIZE", "100"))
+	cfg.Queue.VisibilityTimeout = mustDuration(getEnv("QUEUE_VISIBILITY_TIMEOUT", "10m"))
+	cfg.Queue.MaxAttempts = mustInt(getEnv("QUEUE_MAX_ATTEMPTS", "5"))
+	cfg.Queue.RetryBase = mustDuration(getEnv("QUEUE_RETRY_BASE", "30s"))
+	cfg.Queue.RetryMax = mustDuration(getEnv("QUEUE_RETRY_MAX", "30m"))
+
+	cfg.Verifier.Enabled = mustBool(getEnv("VERIFY_KEYS", "false"))
+	cfg.Verifier.Timeout = mustDuration(getEnv("VERIFY_TIMEOUT", "10s"))
+	cfg.Verifier.BaseURLs = mustMap(getEnv("VERIFY_BASE_URLS", ""))
+	cfg.Verifier.RecheckBase = mustDuration(getEnv("VERIFY_RECHECK_BASE", "1h"))
+	cfg.Verifier.RecheckMax = mustDuration(getEnv("VERIFY_RECHECK_MAX", "168h"))
+
+	cfg.GitHub.APIURL = strings.TrimRight(getEnv("GITHUB_API_URL", "https://api.github.com"), "/")
+	cfg.GitHub.WebURL = strings.TrimRight(getEnv("GITHUB_WEB_URL", githubWebURL(cfg.GitHub.APIURL)), "/")
+	cfg.GitHub.CloneHost = getEnv("GITHUB_CLONE_HOST", "")
+	cfg.GitHub.Tokens = mustList(getEnv("GITHUB_TOKENS", ""))
+	if token := getEnv("GITHUB_TOKEN", ""); token != "" {
+		cfg.GitHub.Tokens = append([]string{token}, cfg.GitHub.Tokens...)
+	}
+	cfg.GitHub.AppID = getEnv("GITHUB_APP_ID", "")
+	cfg.GitHub.AppInstallationIDs = mustList(getEnv("GITHUB_APP_INSTALLATION_IDS", ""))
+	cfg.GitHub.AppPrivateKeyFile = getEnv("GITHUB_APP_PRIVATE_KEY_FILE", "")
 
-	cfg.GitHub.Key = required("GITHUB_TOKEN")
+	cfg.GitLab.URL = getEnv("GITLAB_URL", "")
+	cfg.GitLab.Token = getEnv("GITLAB_TOKEN", "")
+	cfg.Gitea.URL = getEnv("GITEA_URL", "")
+	cfg.Gitea.Token = getEnv("GITEA_TOKEN", "")
+
+	cfg.Admin.Token = getEnv("ADMIN_TOKEN", "")
 
 	cfg.HTTP.Port = required("PORT")
 
@@ -55,6 +155,16 @@ func Load() Config {
 	return cfg
 }
 
+// githubWebURL guesses the web address from the API one: api.github.com is
+// served from github.com, and GitHub Enterprise Server's API lives under
+// /api/v3 on the same host.
+func githubWebURL(apiURL string) string {
+	if apiURL == "https://api.github.com" {
+		return "https://github.com"
+	}
+	return strings.TrimSuffix(apiURL, "/api/v3")
+}
+
 func getEnv(key, fallback string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
@@ -79,6 +189,49 @@ func mustInt(val string) int {
 	return i
 }
 
+func mustFloat(val string) float64 {
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func mustBool(val string) bool {
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// mustList parses "a,b" style values.
+func mustList(val string) []string {
+	var list []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// mustMap parses "a=1,b=2" style values.
+func mustMap(val string) map[string]string {
+	m := make(map[string]string)
+	if val == "" {
+		return m
+	}
+	for _, pair := range strings.Split(val, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			panic("invalid key=value pair: " + pair)
+		}
+		m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return m
+}
+
 func mustDuration(val string) time.Duration {
 	d, err := time.ParseDuration(val)
 	if err != nil {
@@ -1,10 +1,62 @@
 package config
 
+import "net/url"
+
 func validate(cfg Config) {
+	if len(cfg.GitHub.Tokens) == 0 && cfg.GitHub.AppID == "" {
+		panic("missing required env: GITHUB_TOKEN, GITHUB_TOKENS or GITHUB_APP_ID")
+	}
+	if !isURL(cfg.GitHub.APIURL) {
+		panic("invalid GITHUB_API_URL")
+	}
+	if !isURL(cfg.GitHub.WebURL) {
+		panic("invalid GITHUB_WEB_URL")
+	}
+	if cfg.GitHub.AppID != "" && (len(cfg.GitHub.AppInstallationIDs) == 0 || cfg.GitHub.AppPrivateKeyFile == "") {
+		panic("GITHUB_APP_ID needs GITHUB_APP_INSTALLATION_IDS and GITHUB_APP_PRIVATE_KEY_FILE")
+	}
 	if cfg.Scanner.MaxRepoSizeMB <= 0 {
 		panic("invalid SCAN_MAX_REPO_MB")
 	}
 	if cfg.Scanner.MaxConcurrentClones <= 0 {
 		panic("invalid SCAN_MAX_CONCURRENT")
 	}
+	switch cfg.Scanner.HistoryMode {
+	case HistoryHead, HistoryFull:
+	case HistoryCommits, HistoryDays:
+		if cfg.Scanner.HistoryDepth <= 0 {
+			panic("invalid SCAN_HISTORY_DEPTH")
+		}
+	default:
+		panic("invalid SCAN_HISTORY_MODE")
+	}
+	if cfg.Queue.Backend != "postgres" && cfg.Queue.Backend != "memory" {
+		panic("invalid QUEUE_BACKEND")
+	}
+	if cfg.Queue.Size <= 0 {
+		panic("invalid QUEUE_SIZE")
+	}
+	if cfg.Queue.VisibilityTimeout <= 0 {
+		panic("invalid QUEUE_VISIBILITY_TIMEOUT")
+	}
+	if cfg.Queue.MaxAttempts <= 0 {
+		panic("invalid QUEUE_MAX_ATTEMPTS")
+	}
+	if cfg.Queue.RetryBase <= 0 || cfg.Queue.RetryMax < cfg.Queue.RetryBase {
+		panic("invalid QUEUE_RETRY_BASE / QUEUE_RETRY_MAX")
+	}
+	if cfg.Scanner.CloneTimeout <= 0 {
+		panic("invalid SCAN_CLONE_TIMEOUT")
+	}
+	if cfg.Verifier.Timeout <= 0 {
+		panic("invalid VERIFY_TIMEOUT")
+	}
+	if cfg.Verifier.RecheckBase <= 0 || cfg.Verifier.RecheckMax < cfg.Verifier.RecheckBase {
+		panic("invalid VERIFY_RECHECK_BASE / VERIFY_RECHECK_MAX")
+	}
+}
+
+func isURL(val string) bool {
+	u, err := url.Parse(val)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
 }
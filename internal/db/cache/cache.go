@@ -15,8 +15,19 @@ var (
 	LeaderboardMu     sync.RWMutex
 )
 
+var (
+	CachedRevocationMetrics domain.RevocationMetrics
+	RevocationMu            sync.RWMutex
+)
+
 func GetCachedLeaderboard() []domain.LeaderboardEntry {
 	LeaderboardMu.RLock()
 	defer LeaderboardMu.RUnlock()
 	return CachedLeaderboard
 }
+
+func GetCachedRevocationMetrics() domain.RevocationMetrics {
+	RevocationMu.RLock()
+	defer RevocationMu.RUnlock()
+	return CachedRevocationMetrics
+}
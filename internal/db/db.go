@@ -28,7 +28,7 @@ func New(url string) (*gorm.DB, error) {
 	sqlDB.SetMaxOpenConns(25)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	if err := db.AutoMigrate(&domain.Repository{}, &domain.Finding{}); err != nil {
+	if err := db.AutoMigrate(&domain.Repository{}, &domain.Finding{}, &domain.ScanJob{}, &domain.ScanJobTransition{}, &domain.EventCursor{}); err != nil {
 		return nil, fmt.Errorf("auto-migrate failed: %w", err)
 	}
 
@@ -109,6 +109,133 @@ func GetFindingsByRepo(db *gorm.DB, repoName string) ([]domain.Finding, error) {
 	return findings, nil
 }
 
+// Get live findings whose next re-verification is due
+func GetFindingsDueForVerification(db *gorm.DB, now time.Time, limit int) ([]domain.Finding, error) {
+	var findings []domain.Finding
+	result := db.
+		Where("verified_status = ?", domain.VerificationLive).
+		Where("next_verification_at IS NULL OR next_verification_at <= ?", now).
+		Order("next_verification_at ASC NULLS FIRST").
+		Limit(limit).
+		Find(&findings)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch findings: %w", result.Error)
+	}
+	return findings, nil
+}
+
+// Get every finding that has been verified live at some point
+func GetEverLiveFindings(db *gorm.DB) ([]domain.Finding, error) {
+	var findings []domain.Finding
+	result := db.
+		Select("repo_name", "provider", "detected_at", "verified_status", "revoked_at").
+		Where("verified_status = ? OR revoked_at IS NOT NULL", domain.VerificationLive).
+		Find(&findings)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch findings: %w", result.Error)
+	}
+	return findings, nil
+}
+
+// Overwrite Finding
+func UpdateFinding(finding *domain.Finding, db *gorm.DB) error {
+	result := db.Save(finding)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update finding: %w", result.Error)
+	}
+	return nil
+}
+
+// Create or overwrite a scan job, and log its latest status changes
+func SaveScanJob(job *domain.ScanJob, db *gorm.DB, transitions ...domain.ScanJobTransition) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(job).Error; err != nil {
+			return fmt.Errorf("failed to save scan job: %w", err)
+		}
+		for i := range transitions {
+			if err := tx.Create(&transitions[i]).Error; err != nil {
+				return fmt.Errorf("failed to save scan job transition: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Get Scan Job by ID
+func GetScanJob(id string, db *gorm.DB) (*domain.ScanJob, error) {
+	var job domain.ScanJob
+	result := db.First(&job, "id = ?", id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("scan job not found")
+		}
+		return nil, fmt.Errorf("failed to fetch scan job: %w", result.Error)
+	}
+	return &job, nil
+}
+
+// Get Status Changes of a Scan Job
+func GetScanJobTransitions(id string, db *gorm.DB) ([]domain.ScanJobTransition, error) {
+	var transitions []domain.ScanJobTransition
+	result := db.Where("scan_job_id = ?", id).Order("at ASC, id ASC").Find(&transitions)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch scan job transitions: %w", result.Error)
+	}
+	return transitions, nil
+}
+
+// Remove finished Scan Jobs older than cutoff
+func DeleteFinishedScanJobs(cutoff time.Time, db *gorm.DB) (int64, error) {
+	finished := []domain.ScanJobStatus{domain.JobStatusCompleted, domain.JobStatusFailed}
+
+	var deleted int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		old := tx.Model(&domain.ScanJob{}).Select("id").Where("status IN ? AND updated_at < ?", finished, cutoff)
+		if err := tx.Where("scan_job_id IN (?)", old).Delete(&domain.ScanJobTransition{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("status IN ? AND updated_at < ?", finished, cutoff).Delete(&domain.ScanJob{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete scan jobs: %w", err)
+	}
+	return deleted, nil
+}
+
+// Get the Event Cursor of a source, or a fresh one if it's never been polled
+func GetEventCursor(source string, db *gorm.DB) (*domain.EventCursor, error) {
+	var cursor domain.EventCursor
+	result := db.First(&cursor, "source = ?", source)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return domain.NewEventCursor(source), nil
+		}
+		return nil, fmt.Errorf("failed to fetch event cursor: %w", result.Error)
+	}
+	return &cursor, nil
+}
+
+// Get the Event Cursors of every source that's been polled
+func GetEventCursors(db *gorm.DB) ([]domain.EventCursor, error) {
+	var cursors []domain.EventCursor
+	result := db.Order("source").Find(&cursors)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to fetch event cursors: %w", result.Error)
+	}
+	return cursors, nil
+}
+
+// Create or overwrite an Event Cursor
+func SaveEventCursor(cursor *domain.EventCursor, db *gorm.DB) error {
+	result := db.Save(cursor)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save event cursor: %w", result.Error)
+	}
+	return nil
+}
+
 // Overwrite Repository
 func UpdateRepository(repo *domain.Repository, db *gorm.DB) error {
 	result := db.Save(repo)
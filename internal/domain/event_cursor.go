@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+)
+
+// EventCursor is how far through a source's public event feed we've got,
+// so a restart carries on from the last event instead of starting over.
+type EventCursor struct {
+	Source              string    `json:"source" gorm:"primaryKey"`
+	LastEventID         int64     `json:"last_event_id"`
+	Events              int64     `json:"events"`                // new events seen in total
+	Gaps                int64     `json:"gaps"`                  // polls where the feed had moved past LastEventID
+	PollIntervalSeconds int       `json:"poll_interval_seconds"` // adapts to how busy the feed is
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+func (c *EventCursor) PollInterval() time.Duration {
+	return time.Duration(c.PollIntervalSeconds) * time.Second
+}
+
+func NewEventCursor(source string) *EventCursor {
+	return &EventCursor{
+		Source:    source,
+		UpdatedAt: time.Now(),
+	}
+}
@@ -7,13 +7,35 @@ import (
 )
 
 type Finding struct {
-	ID         string    `json:"id"`
-	ScanJobID  string    `json:"scan_job_id"`
-	RepoName   string    `json:"repo_name"`
-	FilePath   string    `json:"file_path"`
-	DetectedAt time.Time `json:"detected_at"`
-	Key        string    `json:"key"`
-	Provider   string    `json:"provider"`
+	ID        string `json:"id"`
+	ScanJobID string `json:"scan_job_id"`
+	RepoName  string `json:"repo_name"`
+	// Filled in from the repository's source when findings are served
+	RepoDisplayName string    `json:"repo_display_name" gorm:"-"`
+	RepoURL         string    `json:"repo_url" gorm:"-"`
+	RepoSource      string    `json:"repo_source" gorm:"-"` // which links to files depend on
+	FilePath        string    `json:"file_path"`
+	DetectedAt      time.Time `json:"detected_at"`
+	Key             string    `json:"key"`
+	Provider        string    `json:"provider"`
+	Offset          int       `json:"offset"`
+	Line            int       `json:"line"`
+	Column          int       `json:"column"`
+	Snippet         string    `json:"snippet"` // surrounding lines, key redacted
+	Confidence      string    `json:"confidence" gorm:"default:high"`
+
+	CommitSHA   string     `json:"commit_sha"`
+	Branch      string     `json:"branch"`
+	AuthorName  string     `json:"author_name"`
+	AuthorEmail string     `json:"author_email"`
+	CommittedAt *time.Time `json:"committed_at"`
+
+	VerifiedStatus       VerificationStatus   `json:"verified_status"`
+	VerifiedAt           *time.Time           `json:"verified_at"`
+	VerificationMetadata VerificationMetadata `json:"verification_metadata" gorm:"type:jsonb"`
+	RevokedAt            *time.Time           `json:"revoked_at"`
+	NextVerificationAt   *time.Time           `json:"-" gorm:"index"`
+	LiveChecks           int                  `json:"-"`
 }
 
 type PaginatedFindings struct {
@@ -24,7 +46,7 @@ type PaginatedFindings struct {
 	TotalPages int       `json:"total_pages"`
 }
 
-func NewFinding(scanJobID, repoName, filePath string, key string, provider string) *Finding {
+func NewFinding(scanJobID, repoName, filePath string, key string, provider string, offset, line, column int) *Finding {
 	return &Finding{
 		ID:         uuid.New().String(),
 		ScanJobID:  scanJobID,
@@ -33,5 +55,30 @@ func NewFinding(scanJobID, repoName, filePath string, key string, provider strin
 		Provider:   provider,
 		DetectedAt: time.Now(),
 		Key:        key,
+		Offset:     offset,
+		Line:       line,
+		Column:     column,
 	}
 }
+
+func (f *Finding) SetVerification(status VerificationStatus, metadata VerificationMetadata) {
+	now := time.Now()
+	f.VerifiedStatus = status
+	f.VerifiedAt = &now
+	f.VerificationMetadata = metadata
+}
+
+// ScheduleReverification backs off exponentially from base (capped at max)
+// for every consecutive check that found the key still live.
+func (f *Finding) ScheduleReverification(base, max time.Duration) {
+	interval := base
+	for i := 1; i < f.LiveChecks && interval < max; i++ {
+		interval *= 2
+	}
+	if interval > max {
+		interval = max
+	}
+
+	next := time.Now().Add(interval)
+	f.NextVerificationAt = &next
+}
@@ -1,8 +1,27 @@
 package domain
 
+import "time"
+
 type LeaderboardEntry struct {
 	Username string `json:"username"`
 	RepoName string `json:"repo_name"`
 	Leaks    int    `json:"leaks"`
 	Avatar   string `json:"avatar"`
 }
+
+// TimeToRevoke is how long leaked keys stayed live, grouped by provider or
+// owner. Revocation is only noticed on re-verification, so times are an upper
+// bound that's as accurate as the re-check interval.
+type TimeToRevoke struct {
+	Name          string  `json:"name"`
+	Revoked       int     `json:"revoked"`
+	StillLive     int     `json:"still_live"`
+	MeanSeconds   float64 `json:"mean_seconds"`
+	MedianSeconds float64 `json:"median_seconds"`
+}
+
+type RevocationMetrics struct {
+	ByProvider []TimeToRevoke `json:"by_provider"`
+	ByOwner    []TimeToRevoke `json:"by_owner"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
@@ -12,15 +12,32 @@ const (
 	JobStatusPending    ScanJobStatus = "pending"
 	JobStatusInProgress ScanJobStatus = "in_progress"
 	JobStatusCompleted  ScanJobStatus = "completed"
-	JobStatusFailed     ScanJobStatus = "failed"
+	JobStatusFailed     ScanJobStatus = "failed" // won't be retried
+	JobStatusDead       ScanJobStatus = "dead"   // ran out of attempts
 )
 
 type ScanJob struct {
-	ID            string        `json:"id"`
+	ID            string        `json:"id" gorm:"primaryKey"`
 	RepositoryURL string        `json:"repository_url"`
 	Status        ScanJobStatus `json:"status"`
+	BeforeSHA     string        `json:"before_sha,omitempty"` // set for push jobs
+	HeadSHA       string        `json:"head_sha,omitempty"`
+	Branch        string        `json:"branch,omitempty"`
 	CreatedAt     time.Time     `json:"created_at"`
 	UpdatedAt     time.Time     `json:"updated_at"`
+
+	StartedAt    *time.Time `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at"`
+	DurationMS   int64      `json:"duration_ms"`
+	Error        string     `json:"error,omitempty"`
+	BytesCloned  int64      `json:"bytes_cloned"`
+	FileCount    int        `json:"file_count"`
+	FindingCount int        `json:"finding_count"`
+	Attempts     int        `json:"attempts"`
+	AvailableAt  *time.Time `json:"available_at"` // not retried before this
+
+	DedupeKey   string     `json:"-"`
+	LockedUntil *time.Time `json:"-"` // claimed jobs become visible again after this
 }
 
 func NewScanJob(repositoryURL string) *ScanJob {
@@ -33,3 +50,65 @@ func NewScanJob(repositoryURL string) *ScanJob {
 		UpdatedAt:     now,
 	}
 }
+
+// ScanJobTransition is one status change in a job's life.
+type ScanJobTransition struct {
+	ID        uint          `json:"-" gorm:"primaryKey"`
+	ScanJobID string        `json:"-" gorm:"index"`
+	From      ScanJobStatus `json:"from"`
+	To        ScanJobStatus `json:"to"`
+	Error     string        `json:"error,omitempty"`
+	At        time.Time     `json:"at"`
+}
+
+type ScanJobDetails struct {
+	ScanJob
+	Transitions []ScanJobTransition `json:"transitions"`
+}
+
+type PaginatedScanJobs struct {
+	Jobs       []ScanJob `json:"jobs"`
+	Page       int       `json:"page"`
+	PageSize   int       `json:"page_size"`
+	TotalCount int64     `json:"total_count"`
+	TotalPages int       `json:"total_pages"`
+}
+
+// MarkStarted moves the job to in progress and returns the transition.
+// Queues may already have flagged the job as claimed, so it's always
+// recorded as coming from pending.
+func (j *ScanJob) MarkStarted() ScanJobTransition {
+	now := time.Now()
+	transition := ScanJobTransition{ScanJobID: j.ID, From: JobStatusPending, To: JobStatusInProgress, At: now}
+
+	j.Status = JobStatusInProgress
+	j.StartedAt = &now
+	j.FinishedAt = nil
+	j.Error = ""
+	j.UpdatedAt = now
+	return transition
+}
+
+// Transition records a status change made outside MarkStarted/MarkFinished,
+// e.g. by a queue's retry policy.
+func (j *ScanJob) Transition(from ScanJobStatus) ScanJobTransition {
+	return ScanJobTransition{ScanJobID: j.ID, From: from, To: j.Status, At: time.Now()}
+}
+
+// MarkFinished moves the job to status and returns the transition.
+func (j *ScanJob) MarkFinished(status ScanJobStatus, cause error) ScanJobTransition {
+	now := time.Now()
+	transition := ScanJobTransition{ScanJobID: j.ID, From: j.Status, To: status, At: now}
+
+	j.Status = status
+	j.FinishedAt = &now
+	j.UpdatedAt = now
+	if j.StartedAt != nil {
+		j.DurationMS = now.Sub(*j.StartedAt).Milliseconds()
+	}
+	if cause != nil {
+		j.Error = cause.Error()
+		transition.Error = j.Error
+	}
+	return transition
+}
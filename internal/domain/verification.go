@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+type VerificationStatus string
+
+const (
+	VerificationLive    VerificationStatus = "live"
+	VerificationInvalid VerificationStatus = "invalid"
+	VerificationUnknown VerificationStatus = "unknown"
+)
+
+// VerificationMetadata is whatever account details a provider hands back for
+// a live key (team, label, usage...). Stored as jsonb.
+type VerificationMetadata map[string]string
+
+func (m VerificationMetadata) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (m *VerificationMetadata) Scan(value any) error {
+	switch value := value.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(value, m)
+	case string:
+		return json.Unmarshal([]byte(value), m)
+	default:
+		return fmt.Errorf("cannot scan %T into VerificationMetadata", value)
+	}
+}
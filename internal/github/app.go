@@ -0,0 +1,152 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Installation tokens last an hour; they're swapped for a new one once
+// there's less than this left, so requests never go out with a token that
+// expires mid-flight.
+const tokenRefreshMargin = 5 * time.Minute
+
+// AppInstallation is a TokenSource for a GitHub App installation. It signs
+// a short-lived JWT with the app's private key and exchanges it for an
+// installation token, which is cached until it's about to expire.
+type AppInstallation struct {
+	BaseURL        string
+	AppID          string
+	InstallationID string
+
+	key  *rsa.PrivateKey
+	http *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func NewAppInstallation(baseURL string, appID string, installationID string, privateKeyPEM []byte) (*AppInstallation, error) {
+	key, err := parsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &AppInstallation{
+		BaseURL:        baseURL,
+		AppID:          appID,
+		InstallationID: installationID,
+		key:            key,
+		http:           &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (a *AppInstallation) Name() string {
+	return "app-" + a.AppID + "/installation-" + a.InstallationID
+}
+
+func (a *AppInstallation) Token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && time.Until(a.expiresAt) > tokenRefreshMargin {
+		return a.token, nil
+	}
+
+	token, expiresAt, err := a.exchange(ctx)
+	if err != nil {
+		return "", err
+	}
+	a.token, a.expiresAt = token, expiresAt
+	return token, nil
+}
+
+// exchange swaps an app JWT for a fresh installation token.
+func (a *AppInstallation) exchange(ctx context.Context) (string, time.Time, error) {
+	jwt, err := a.JWT(time.Now())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	url := a.BaseURL + "/app/installations/" + a.InstallationID + "/access_tokens"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create req: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	res, err := a.http.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("http call failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return "", time.Time{}, fmt.Errorf("installation token exchange returned %s", res.Status)
+	}
+
+	var body struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", time.Time{}, fmt.Errorf("json decode failed: %w", err)
+	}
+	if body.Token == "" {
+		return "", time.Time{}, errors.New("installation token exchange returned no token")
+	}
+	return body.Token, body.ExpiresAt, nil
+}
+
+// JWT signs the app's own token. It's backdated a minute to allow for clock
+// drift, and GitHub rejects any that last longer than ten minutes.
+func (a *AppInstallation) JWT(now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": a.AppID,
+	})
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign app jwt: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey reads the PEM key GitHub hands out (PKCS#1), or the same
+// key converted to PKCS#8.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("app private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse app private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("app private key is not an RSA key")
+	}
+	return key, nil
+}
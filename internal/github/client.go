@@ -0,0 +1,319 @@
+// A GitHub REST client that plays by the API's rules: it sends conditional
+// requests, honours X-Poll-Interval, and backs off when it hits a primary or
+// secondary rate limit instead of hammering the API until the token is banned.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultBaseURL = "https://api.github.com"
+
+// Bodies bigger than maxCachedBody aren't kept for conditional requests,
+// and entries are evicted to keep the cache under maxCachedBytes.
+const (
+	maxCachedBody  = 256 * 1024
+	maxCachedBytes = 32 << 20
+)
+
+// GitHub asks for at least a minute between retries after a secondary rate
+// limit that doesn't say how long to wait.
+const secondaryBackoff = time.Minute
+
+// ErrNotDue means a poll was skipped because X-Poll-Interval hasn't passed.
+var ErrNotDue = errors.New("poll interval has not passed")
+
+// RateLimitError is returned while the client is backing off.
+type RateLimitError struct {
+	Until     time.Time
+	Secondary bool
+}
+
+func (e *RateLimitError) Error() string {
+	kind := "primary"
+	if e.Secondary {
+		kind = "secondary"
+	}
+	return fmt.Sprintf("github %s rate limit, backing off until %s", kind, e.Until.Format(time.RFC3339))
+}
+
+// StatusError is any other unsuccessful response.
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return "github returned " + e.Status
+}
+
+// RateLimit is the quota left on a token, as of its last response.
+type RateLimit struct {
+	Limit        int       `json:"limit"`
+	Remaining    int       `json:"remaining"`
+	Reset        time.Time `json:"reset"`
+	BlockedUntil time.Time `json:"blocked_until"` // zero unless backing off
+}
+
+// Response describes how a request was answered.
+type Response struct {
+	StatusCode   int
+	NotModified  bool // answered from the cache by a 304
+	PollInterval time.Duration
+	NextURL      string // the next page, from the Link header
+}
+
+type cachedResponse struct {
+	etag string
+	body []byte
+}
+
+func (r cachedResponse) size(url string) int {
+	return len(url) + len(r.etag) + len(r.body)
+}
+
+type Client struct {
+	BaseURL string
+
+	http *http.Client
+	pool *Pool
+
+	mu         sync.Mutex
+	cache      map[string]cachedResponse
+	cacheBytes int
+	nextPoll   map[string]time.Time
+}
+
+func NewClient(baseURL string, pool *Pool) *Client {
+	return &Client{
+		BaseURL: baseURL,
+		http: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				MaxIdleConns:        20,
+				MaxIdleConnsPerHost: 10,
+				IdleConnTimeout:     30 * time.Second,
+			},
+		},
+		pool:     pool,
+		cache:    make(map[string]cachedResponse),
+		nextPoll: make(map[string]time.Time),
+	}
+}
+
+// RateLimit reports the quota left across every credential.
+func (c *Client) RateLimit() RateLimit {
+	return c.pool.RateLimit()
+}
+
+// Tokens reports each credential's quota and usage.
+func (c *Client) Tokens() []TokenUsage {
+	return c.pool.Usage()
+}
+
+// Get fetches url and decodes the JSON body into v. If url was fetched
+// before, the request is conditional: a 304 doesn't count against the rate
+// limit, and v is filled in from the cached body. A request that hits a
+// rate limit is retried with the next credential, if there is one.
+func (c *Client) Get(ctx context.Context, url string, v any) (*Response, error) {
+	var (
+		res *http.Response
+		err error
+	)
+	for range max(c.pool.Len(), 1) {
+		res, err = c.do(ctx, url)
+		var rateLimitErr *RateLimitError
+		if !errors.As(err, &rateLimitErr) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	c.mu.Lock()
+	cached, hasCached := c.cache[url]
+	c.mu.Unlock()
+
+	response := &Response{
+		StatusCode:   res.StatusCode,
+		PollInterval: pollInterval(res.Header),
+		NextURL:      nextURL(res.Header),
+	}
+
+	var body []byte
+	switch {
+	case res.StatusCode == http.StatusNotModified && hasCached:
+		response.NotModified = true
+		body = cached.body
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		body, err = io.ReadAll(res.Body)
+		if err != nil {
+			return response, fmt.Errorf("failed to read body: %w", err)
+		}
+		if etag := res.Header.Get("ETag"); etag != "" {
+			c.remember(url, cachedResponse{etag: etag, body: body})
+		}
+	default:
+		return response, &StatusError{StatusCode: res.StatusCode, Status: res.Status}
+	}
+
+	if v != nil {
+		if err := json.NewDecoder(bytes.NewReader(body)).Decode(v); err != nil {
+			return response, fmt.Errorf("json decode failed: %w", err)
+		}
+	}
+	return response, nil
+}
+
+// do makes one request with the credential that has the most quota left,
+// or anonymously if the pool is empty.
+func (c *Client) do(ctx context.Context, url string) (*http.Response, error) {
+	var m *member
+	if c.pool.Len() > 0 {
+		var err error
+		if m, err = c.pool.acquire(); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create req: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if m != nil {
+		token, err := m.source.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get token for %s: %w", m.source.Name(), err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	c.mu.Lock()
+	if cached, ok := c.cache[url]; ok {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+	c.mu.Unlock()
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http call failed: %w", err)
+	}
+	if m == nil {
+		return res, nil
+	}
+	if err := c.pool.record(m, res); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	return res, nil
+}
+
+// CloneURL adds credentials to a repository's clone URL, so the clone is
+// made as one of our tokens (or app installations) instead of anonymously.
+func (c *Client) CloneURL(ctx context.Context, cloneURL string) (string, error) {
+	m, err := c.pool.peek()
+	if err != nil {
+		return "", err
+	}
+	token, err := m.source.Token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get token for %s: %w", m.source.Name(), err)
+	}
+
+	u, err := url.Parse(cloneURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid clone url: %w", err)
+	}
+	u.User = url.UserPassword("x-access-token", token)
+	return u.String(), nil
+}
+
+// Poll is Get for endpoints that set X-Poll-Interval, like /events. Until
+// interval, or X-Poll-Interval if that's longer, has passed since the last
+// poll of url, it returns ErrNotDue without making a request.
+func (c *Client) Poll(ctx context.Context, url string, interval time.Duration, v any) (*Response, error) {
+	c.mu.Lock()
+	due := c.nextPoll[url]
+	c.mu.Unlock()
+	if time.Now().Before(due) {
+		return nil, ErrNotDue
+	}
+
+	response, err := c.Get(ctx, url, v)
+
+	if response != nil && response.PollInterval > interval {
+		interval = response.PollInterval
+	}
+	c.mu.Lock()
+	c.nextPoll[url] = time.Now().Add(interval)
+	c.mu.Unlock()
+
+	return response, err
+}
+
+func (c *Client) remember(url string, response cachedResponse) {
+	if len(response.body) > maxCachedBody {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if old, ok := c.cache[url]; ok {
+		c.cacheBytes -= old.size(url)
+		delete(c.cache, url)
+	}
+	for evict, old := range c.cache { // any entries will do
+		if c.cacheBytes+response.size(url) <= maxCachedBytes {
+			break
+		}
+		c.cacheBytes -= old.size(evict)
+		delete(c.cache, evict)
+	}
+	c.cache[url] = response
+	c.cacheBytes += response.size(url)
+}
+
+func pollInterval(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("X-Poll-Interval"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// nextURL finds the rel="next" link in a Link header like
+// <https://api.github.com/events?page=2>; rel="next", <...>; rel="last".
+func nextURL(header http.Header) string {
+	for _, link := range strings.Split(header.Get("Link"), ",") {
+		target, params, ok := strings.Cut(link, ";")
+		if !ok || !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(target), "<>")
+	}
+	return ""
+}
+
+// parseRetryAfter reads a Retry-After header. GitHub only sends seconds.
+func parseRetryAfter(val string) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(val)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
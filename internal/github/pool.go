@@ -0,0 +1,234 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var ErrNoCredentials = errors.New("no github credentials configured")
+
+// TokenSource is one credential in a Pool.
+type TokenSource interface {
+	// Name identifies the credential on the status endpoint without
+	// giving it away.
+	Name() string
+	Token(ctx context.Context) (string, error)
+}
+
+type staticToken struct {
+	name  string
+	token string
+}
+
+// StaticToken is a personal access token.
+func StaticToken(token string) TokenSource {
+	return staticToken{name: maskToken(token), token: token}
+}
+
+func (t staticToken) Name() string {
+	return t.name
+}
+
+func (t staticToken) Token(ctx context.Context) (string, error) {
+	return t.token, nil
+}
+
+func maskToken(token string) string {
+	if len(token) <= 8 {
+		return "****"
+	}
+	return token[:4] + "..." + token[len(token)-4:]
+}
+
+// TokenUsage is what the status endpoint shows for each credential.
+type TokenUsage struct {
+	Name string `json:"name"`
+	RateLimit
+	Requests int64 `json:"requests"`
+}
+
+type member struct {
+	source    TokenSource
+	rateLimit RateLimit
+	known     bool // a response has told us the quota
+	requests  int64
+}
+
+// remaining is the quota we think is left. Until GitHub tells us otherwise,
+// and once the window has reset, that's all of it.
+func (m *member) remaining(now time.Time) int {
+	if !m.known || now.After(m.rateLimit.Reset) {
+		return math.MaxInt
+	}
+	return m.rateLimit.Remaining
+}
+
+// Pool spreads requests across several credentials, each with its own rate
+// limit. Credentials that run out are taken out of rotation until they reset.
+type Pool struct {
+	mu      sync.Mutex
+	members []*member
+}
+
+func NewPool(sources ...TokenSource) *Pool {
+	pool := &Pool{}
+	for _, source := range sources {
+		pool.Add(source)
+	}
+	return pool
+}
+
+func (p *Pool) Add(source TokenSource) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.members = append(p.members, &member{source: source})
+}
+
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.members)
+}
+
+// acquire picks the credential with the most quota left for an API request.
+// If every one of them is backing off, it says until when.
+func (p *Pool) acquire() (*member, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	best, err := p.best()
+	if err != nil {
+		return nil, err
+	}
+
+	// Count the request now so concurrent callers spread out.
+	best.requests++
+	if best.known && best.rateLimit.Remaining > 0 {
+		best.rateLimit.Remaining--
+	}
+	return best, nil
+}
+
+// peek is acquire for things that don't use up API quota, like clones.
+func (p *Pool) peek() (*member, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.best()
+}
+
+func (p *Pool) best() (*member, error) {
+	if len(p.members) == 0 {
+		return nil, ErrNoCredentials
+	}
+
+	now := time.Now()
+	var best *member
+	var blocked *RateLimitError
+	for _, m := range p.members {
+		if now.Before(m.rateLimit.BlockedUntil) {
+			if blocked == nil || m.rateLimit.BlockedUntil.Before(blocked.Until) {
+				blocked = &RateLimitError{Until: m.rateLimit.BlockedUntil, Secondary: m.rateLimit.Remaining > 0}
+			}
+			continue
+		}
+		if best == nil || m.remaining(now) > best.remaining(now) {
+			best = m
+		}
+	}
+	if best == nil {
+		return nil, blocked
+	}
+	return best, nil
+}
+
+// record updates a credential's quota from a response's headers, and takes
+// it out of rotation if it's been rate limited or used up.
+func (p *Pool) record(m *member, res *http.Response) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rateLimit := &m.rateLimit
+	if limit, err := strconv.Atoi(res.Header.Get("X-RateLimit-Limit")); err == nil {
+		rateLimit.Limit = limit
+	}
+	if remaining, err := strconv.Atoi(res.Header.Get("X-RateLimit-Remaining")); err == nil {
+		rateLimit.Remaining = remaining
+		m.known = true
+	}
+	if reset, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rateLimit.Reset = time.Unix(reset, 0)
+	}
+
+	exhausted := res.Header.Get("X-RateLimit-Remaining") == "0"
+	if exhausted && rateLimit.Reset.After(time.Now()) {
+		rateLimit.BlockedUntil = rateLimit.Reset
+	}
+
+	if res.StatusCode != http.StatusForbidden && res.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	// A 403 with quota left and no Retry-After is a plain permission error.
+	retryAfter, hasRetryAfter := parseRetryAfter(res.Header.Get("Retry-After"))
+	if !hasRetryAfter && !exhausted && res.StatusCode == http.StatusForbidden {
+		return nil
+	}
+
+	rateLimitErr := &RateLimitError{}
+	switch {
+	case hasRetryAfter:
+		rateLimitErr.Until = time.Now().Add(retryAfter)
+		rateLimitErr.Secondary = !exhausted
+	case exhausted && rateLimit.Reset.After(time.Now()):
+		rateLimitErr.Until = rateLimit.Reset
+	default:
+		rateLimitErr.Until = time.Now().Add(secondaryBackoff)
+		rateLimitErr.Secondary = !exhausted
+	}
+	rateLimit.BlockedUntil = rateLimitErr.Until
+	return rateLimitErr
+}
+
+// RateLimit adds up the quota of every credential. BlockedUntil is only set
+// when all of them are backing off.
+func (p *Pool) RateLimit() RateLimit {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var total RateLimit
+	allBlocked := len(p.members) > 0
+	for _, m := range p.members {
+		total.Limit += m.rateLimit.Limit
+		total.Remaining += m.rateLimit.Remaining
+		if total.Reset.IsZero() || m.rateLimit.Reset.Before(total.Reset) {
+			total.Reset = m.rateLimit.Reset
+		}
+		if !now.Before(m.rateLimit.BlockedUntil) {
+			allBlocked = false
+		} else if total.BlockedUntil.IsZero() || m.rateLimit.BlockedUntil.Before(total.BlockedUntil) {
+			total.BlockedUntil = m.rateLimit.BlockedUntil
+		}
+	}
+	if !allBlocked {
+		total.BlockedUntil = time.Time{}
+	}
+	return total
+}
+
+// Usage reports each credential's quota and how many requests it has made.
+func (p *Pool) Usage() []TokenUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	usage := make([]TokenUsage, 0, len(p.members))
+	for _, m := range p.members {
+		usage = append(usage, TokenUsage{Name: m.source.Name(), RateLimit: m.rateLimit, Requests: m.requests})
+	}
+	return usage
+}
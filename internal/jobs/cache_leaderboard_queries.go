@@ -4,19 +4,18 @@ import (
 	"openradar/internal/db"
 	"openradar/internal/db/cache"
 	"openradar/internal/domain"
+	"openradar/internal/sources"
 	"sort"
-	"strings"
 	"time"
 )
 
-func extractUsername(repoName string) string {
-	repoName = strings.TrimPrefix(repoName, "https://api.github.com/repos/")
-	repoName = strings.TrimPrefix(repoName, "https://github.com/")
-	parts := strings.Split(repoName, "/")
-	if len(parts) >= 1 {
-		return parts[0]
+// extractOwner finds who owns a repository and their avatar. Repositories
+// from a source that isn't configured any more are grouped under their URL.
+func extractOwner(repoName string) (string, string) {
+	if identity, ok := sources.IdentityOf(repoName); ok {
+		return identity.Owner, identity.Avatar
 	}
-	return repoName
+	return repoName, ""
 }
 
 func cache_leaderboard_query(jobContext JobContext) {
@@ -34,10 +33,12 @@ func cache_leaderboard_query(jobContext JobContext) {
 
 	userCounts := make(map[string]int)
 	userRepos := make(map[string]string)
+	userAvatars := make(map[string]string)
 	for _, finding := range allFindings {
-		username := extractUsername(finding.RepoName)
+		username, avatar := extractOwner(finding.RepoName)
 		userCounts[username]++
 		userRepos[username] = finding.RepoName
+		userAvatars[username] = avatar
 	}
 
 	var entries []domain.LeaderboardEntry
@@ -46,7 +47,7 @@ func cache_leaderboard_query(jobContext JobContext) {
 			Username: username,
 			RepoName: userRepos[username],
 			Leaks:    count,
-			Avatar:   "https://github.com/" + username + ".png",
+			Avatar:   userAvatars[username],
 		})
 	}
 
@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"openradar/internal/db"
+	"openradar/internal/db/cache"
+	"openradar/internal/domain"
+)
+
+const maxOwnersInMetrics = 100
+
+type revocationTally struct {
+	durations []time.Duration
+	stillLive int
+}
+
+func (t *revocationTally) add(finding domain.Finding) {
+	if finding.RevokedAt == nil {
+		t.stillLive++
+		return
+	}
+	t.durations = append(t.durations, finding.RevokedAt.Sub(finding.DetectedAt))
+}
+
+func (t *revocationTally) summarize(name string) domain.TimeToRevoke {
+	entry := domain.TimeToRevoke{
+		Name:      name,
+		Revoked:   len(t.durations),
+		StillLive: t.stillLive,
+	}
+	if len(t.durations) == 0 {
+		return entry
+	}
+
+	sort.Slice(t.durations, func(i, j int) bool {
+		return t.durations[i] < t.durations[j]
+	})
+
+	var total time.Duration
+	for _, d := range t.durations {
+		total += d
+	}
+	entry.MeanSeconds = total.Seconds() / float64(len(t.durations))
+
+	mid := len(t.durations) / 2
+	if len(t.durations)%2 == 0 {
+		entry.MedianSeconds = (t.durations[mid-1] + t.durations[mid]).Seconds() / 2
+	} else {
+		entry.MedianSeconds = t.durations[mid].Seconds()
+	}
+	return entry
+}
+
+func summarizeTallies(tallies map[string]*revocationTally) []domain.TimeToRevoke {
+	entries := make([]domain.TimeToRevoke, 0, len(tallies))
+	for name, tally := range tallies {
+		entries = append(entries, tally.summarize(name))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Revoked+entries[i].StillLive != entries[j].Revoked+entries[j].StillLive {
+			return entries[i].Revoked+entries[i].StillLive > entries[j].Revoked+entries[j].StillLive
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+func cache_revocation_metrics(jobContext JobContext) {
+	findings, err := db.GetEverLiveFindings(jobContext.DB)
+	if err != nil {
+		log.Printf("failed to fetch findings for revocation metrics: %v", err)
+		return
+	}
+
+	byProvider := make(map[string]*revocationTally)
+	byOwner := make(map[string]*revocationTally)
+	for _, finding := range findings {
+		owner, _ := extractOwner(finding.RepoName)
+		if byProvider[finding.Provider] == nil {
+			byProvider[finding.Provider] = &revocationTally{}
+		}
+		if byOwner[owner] == nil {
+			byOwner[owner] = &revocationTally{}
+		}
+		byProvider[finding.Provider].add(finding)
+		byOwner[owner].add(finding)
+	}
+
+	owners := summarizeTallies(byOwner)
+	if len(owners) > maxOwnersInMetrics {
+		owners = owners[:maxOwnersInMetrics]
+	}
+
+	cache.RevocationMu.Lock()
+	cache.CachedRevocationMetrics = domain.RevocationMetrics{
+		ByProvider: summarizeTallies(byProvider),
+		ByOwner:    owners,
+		UpdatedAt:  time.Now(),
+	}
+	cache.RevocationMu.Unlock()
+}
+
+func init() {
+	RegisterJob(Job{
+		Name:     "Cache time-to-revoke metrics",
+		Func:     cache_revocation_metrics,
+		Schedule: 15 * time.Minute,
+	})
+}
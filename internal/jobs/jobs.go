@@ -3,15 +3,17 @@ package jobs
 import (
 	"context"
 	"openradar/internal/config"
+	"openradar/internal/queue"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type JobContext struct {
-	DB  *gorm.DB
-	Cfg config.Config
-	Ctx context.Context
+	DB    *gorm.DB
+	Cfg   config.Config
+	Ctx   context.Context
+	Queue queue.Queue
 }
 
 type JobFunc func(jobContext JobContext)
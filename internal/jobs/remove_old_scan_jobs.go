@@ -0,0 +1,28 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"openradar/internal/db"
+)
+
+// Finished scan jobs are kept for a week so failures can be looked into
+func removeOldScanJobsFunc(jobContext JobContext) {
+	deleted, err := db.DeleteFinishedScanJobs(time.Now().Add(-7*24*time.Hour), jobContext.DB)
+	if err != nil {
+		log.Printf("failed to remove old scan jobs: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("removed %d old scan jobs", deleted)
+	}
+}
+
+func init() {
+	RegisterJob(Job{
+		Name:     "Remove old scan jobs",
+		Func:     removeOldScanJobsFunc,
+		Schedule: time.Hour,
+	})
+}
@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"openradar/internal/db"
+	"openradar/internal/domain"
+	"openradar/internal/verifier"
+)
+
+const reverifyBatchSize = 100
+
+// Re-checks live keys on a backoff schedule so we know when they get revoked
+func reverifyFindingsFunc(jobContext JobContext) {
+	cfg := jobContext.Cfg.Verifier
+	if !cfg.Enabled {
+		return
+	}
+
+	findings, err := db.GetFindingsDueForVerification(jobContext.DB, time.Now(), reverifyBatchSize)
+	if err != nil {
+		log.Printf("failed to fetch findings to re-verify: %v", err)
+		return
+	}
+
+	for i := range findings {
+		finding := &findings[i]
+
+		verifyCtx, cancel := context.WithTimeout(jobContext.Ctx, cfg.Timeout)
+		result := verifier.Verify(verifyCtx, finding.Provider, finding.Key)
+		cancel()
+
+		switch result.Status {
+		case domain.VerificationLive:
+			finding.SetVerification(result.Status, result.Metadata)
+			finding.LiveChecks++
+			finding.ScheduleReverification(cfg.RecheckBase, cfg.RecheckMax)
+		case domain.VerificationInvalid:
+			finding.SetVerification(result.Status, finding.VerificationMetadata)
+			finding.RevokedAt = finding.VerifiedAt
+			finding.NextVerificationAt = nil
+			log.Printf("%s key in %s was revoked after %s", finding.Provider, finding.RepoName, finding.RevokedAt.Sub(finding.DetectedAt).Round(time.Minute))
+		default: // couldn't tell, try again later without backing off further
+			finding.ScheduleReverification(cfg.RecheckBase, cfg.RecheckMax)
+		}
+
+		if err := db.UpdateFinding(finding, jobContext.DB); err != nil {
+			log.Printf("failed to save re-verified finding %s: %v", finding.ID, err)
+		}
+	}
+}
+
+func init() {
+	RegisterJob(Job{
+		Name:     "Re-verify live findings",
+		Func:     reverifyFindingsFunc,
+		Schedule: 5 * time.Minute,
+	})
+}
@@ -6,17 +6,22 @@ import (
 	"time"
 )
 
+// scanJobFunc runs often, but ScanJob only polls each source as often as
+// its adaptive interval (and GitHub's X-Poll-Interval) allows.
 func scanJobFunc(jobContext JobContext) {
-	log.Println("scanning for latest repo updates")
-	if _, err := scanner.ScanJob(jobContext.Ctx, jobContext.Cfg.GitHub.Key); err != nil {
+	activity, err := scanner.ScanJob(jobContext.Ctx, jobContext.Queue, jobContext.DB, jobContext.Cfg.Scanner.PushDiff)
+	if err != nil {
 		log.Printf("failed to scan for jobs: %v", err)
 	}
+	if len(activity) > 0 {
+		log.Printf("scanned %d new events for repo updates", len(activity))
+	}
 }
 
 func init() {
 	RegisterJob(Job{
 		Name:     "Scan for new repositories (/live)",
 		Func:     scanJobFunc,
-		Schedule: 35 * time.Second,
+		Schedule: 5 * time.Second,
 	})
 }
@@ -0,0 +1,236 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"openradar/internal/domain"
+)
+
+// InMemoryQueue is a bounded channel. Everything in it is lost on restart,
+// so it's meant for tests and throwaway runs. Claims expire like the
+// PostgresQueue's do, unless the visibility timeout is 0, and each worker
+// gets its own copy of a job, as it would from the database.
+type InMemoryQueue struct {
+	jobs              chan *domain.ScanJob
+	visibilityTimeout time.Duration
+	policy            RetryPolicy
+
+	mu     sync.Mutex
+	queued map[string]bool
+	claims map[string]*claim // by job ID
+	dead   map[string]*domain.ScanJob
+}
+
+type claim struct {
+	job   *domain.ScanJob
+	until time.Time
+}
+
+func NewInMemoryQueue(queueSize int, visibilityTimeout time.Duration, policy RetryPolicy) *InMemoryQueue {
+	return &InMemoryQueue{
+		jobs:              make(chan *domain.ScanJob, queueSize),
+		visibilityTimeout: visibilityTimeout,
+		policy:            policy,
+		queued:            make(map[string]bool),
+		claims:            make(map[string]*claim),
+		dead:              make(map[string]*domain.ScanJob),
+	}
+}
+
+func (q *InMemoryQueue) Enqueue(ctx context.Context, job *domain.ScanJob) (bool, error) {
+	job.DedupeKey = dedupeKey(job)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.queued[job.DedupeKey] {
+		return false, nil
+	}
+
+	select {
+	case q.jobs <- job:
+		q.queued[job.DedupeKey] = true
+		return true, nil
+	default:
+		return false, ErrQueueFull
+	}
+}
+
+// Dequeue hands out the next job, or one whose claim has expired and that
+// still has attempts left.
+func (q *InMemoryQueue) Dequeue(ctx context.Context) (*domain.ScanJob, error) {
+	for {
+		job, nextExpiry := q.reclaim()
+		if job != nil {
+			return job, nil
+		}
+
+		var expired <-chan time.Time
+		if !nextExpiry.IsZero() {
+			expired = time.After(time.Until(nextExpiry))
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case job := <-q.jobs:
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			return q.claim(job), nil
+		case <-expired:
+		}
+	}
+}
+
+// reclaim hands out a job whose claim has expired, marking any that expired
+// on their last attempt dead. Otherwise it returns when the next claim
+// expires, or zero if none will.
+func (q *InMemoryQueue) reclaim() (*domain.ScanJob, time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	var nextExpiry time.Time
+	for id, c := range q.claims {
+		if q.visibilityTimeout <= 0 {
+			break
+		}
+		if c.until.After(now) {
+			if nextExpiry.IsZero() || c.until.Before(nextExpiry) {
+				nextExpiry = c.until
+			}
+			continue
+		}
+
+		delete(q.claims, id)
+		if c.job.Attempts >= q.policy.MaxAttempts {
+			c.job.Status = domain.JobStatusDead
+			c.job.Error = "claim expired on the last attempt"
+			q.dead[id] = c.job
+			delete(q.queued, c.job.DedupeKey)
+			continue
+		}
+		return q.claim(c.job), time.Time{}
+	}
+	return nil, nextExpiry
+}
+
+// claim takes another attempt at job and returns the claimant's copy of it.
+// q.mu must be held.
+func (q *InMemoryQueue) claim(job *domain.ScanJob) *domain.ScanJob {
+	job.Attempts++
+	q.claims[job.ID] = &claim{job: job, until: time.Now().Add(q.visibilityTimeout)}
+	claimed := *job
+	return &claimed
+}
+
+// owns reports whether job's claim is still the current one. q.mu must be
+// held.
+func (q *InMemoryQueue) owns(job *domain.ScanJob) bool {
+	c, ok := q.claims[job.ID]
+	return ok && c.job.Attempts == job.Attempts
+}
+
+func (q *InMemoryQueue) Extend(ctx context.Context, job *domain.ScanJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.owns(job) {
+		return ErrClaimLost
+	}
+	q.claims[job.ID].until = time.Now().Add(q.visibilityTimeout)
+	return nil
+}
+
+// unclaim gives up the claim on a finished job.
+func (q *InMemoryQueue) unclaim(job *domain.ScanJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.owns(job) {
+		return ErrClaimLost
+	}
+	delete(q.claims, job.ID)
+	return nil
+}
+
+func (q *InMemoryQueue) Complete(ctx context.Context, job *domain.ScanJob) error {
+	if err := q.unclaim(job); err != nil {
+		return err
+	}
+	q.release(job)
+	return nil
+}
+
+func (q *InMemoryQueue) Fail(ctx context.Context, job *domain.ScanJob, cause error) error {
+	if err := q.unclaim(job); err != nil {
+		return err
+	}
+	q.policy.apply(job, cause)
+	if job.Status != domain.JobStatusPending {
+		if job.Status == domain.JobStatusDead {
+			q.mu.Lock()
+			q.dead[job.ID] = job
+			q.mu.Unlock()
+		}
+		q.release(job)
+		return nil
+	}
+
+	// Still counts as queued while it waits, so it isn't enqueued twice.
+	time.AfterFunc(time.Until(*job.AvailableAt), func() {
+		select {
+		case q.jobs <- job:
+		default:
+			q.release(job)
+		}
+	})
+	return nil
+}
+
+func (q *InMemoryQueue) Requeue(ctx context.Context, id string) (*domain.ScanJob, error) {
+	q.mu.Lock()
+	job, ok := q.dead[id]
+	if ok {
+		delete(q.dead, id)
+	}
+	q.mu.Unlock()
+
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+
+	job.Status = domain.JobStatusPending
+	job.Attempts = 0
+	job.AvailableAt = nil
+	added, err := q.Enqueue(ctx, job)
+	if err != nil || !added {
+		q.mu.Lock()
+		q.dead[id] = job
+		q.mu.Unlock()
+		job.Status = domain.JobStatusDead
+		if err == nil {
+			err = ErrJobActive
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+func (q *InMemoryQueue) DeadJobIDs(ctx context.Context) ([]string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ids := make([]string, 0, len(q.dead))
+	for id := range q.dead {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (q *InMemoryQueue) release(job *domain.ScanJob) {
+	q.mu.Lock()
+	delete(q.queued, job.DedupeKey)
+	q.mu.Unlock()
+}
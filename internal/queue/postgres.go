@@ -0,0 +1,219 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"openradar/internal/domain"
+)
+
+// PostgresQueue keeps jobs in the scan_jobs table so they survive restarts.
+// Workers claim jobs with FOR UPDATE SKIP LOCKED, and a claim only lasts for
+// the visibility timeout: if a worker dies mid-scan, its job is picked up
+// again once the timeout passes.
+type PostgresQueue struct {
+	db                *gorm.DB
+	visibilityTimeout time.Duration
+	policy            RetryPolicy
+	pollInterval      time.Duration
+}
+
+func NewPostgresQueue(db *gorm.DB, visibilityTimeout time.Duration, policy RetryPolicy) (*PostgresQueue, error) {
+	// Only one waiting or running job per repository (or push).
+	// DDL can't take bind parameters, hence the inlined statuses.
+	err := db.Exec(fmt.Sprintf(`CREATE UNIQUE INDEX IF NOT EXISTS idx_scan_jobs_active_dedupe
+		ON scan_jobs (dedupe_key) WHERE status IN ('%s', '%s')`,
+		domain.JobStatusPending, domain.JobStatusInProgress)).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dedupe index: %w", err)
+	}
+
+	return &PostgresQueue{
+		db:                db,
+		visibilityTimeout: visibilityTimeout,
+		policy:            policy,
+		pollInterval:      time.Second,
+	}, nil
+}
+
+func (q *PostgresQueue) Enqueue(ctx context.Context, job *domain.ScanJob) (bool, error) {
+	job.DedupeKey = dedupeKey(job)
+
+	result := q.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(job)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to enqueue job: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Dequeue claims the oldest pending job that's due, or an in-progress one
+// whose claim has expired and that still has attempts left.
+func (q *PostgresQueue) Dequeue(ctx context.Context) (*domain.ScanJob, error) {
+	for {
+		job, err := q.claim(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if job != nil {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(q.pollInterval):
+		}
+	}
+}
+
+func (q *PostgresQueue) claim(ctx context.Context) (*domain.ScanJob, error) {
+	now := time.Now()
+	if err := q.reapExpired(ctx, now); err != nil {
+		return nil, err
+	}
+
+	var jobs []domain.ScanJob
+	result := q.db.WithContext(ctx).Raw(`
+		UPDATE scan_jobs SET status = ?, locked_until = ?, updated_at = ?, attempts = attempts + 1
+		WHERE id = (
+			SELECT id FROM scan_jobs
+			WHERE (status = ? AND (available_at IS NULL OR available_at <= ?))
+				OR (status = ? AND locked_until < ? AND attempts < ?)
+			ORDER BY created_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING *`,
+		domain.JobStatusInProgress, now.Add(q.visibilityTimeout), now,
+		domain.JobStatusPending, now,
+		domain.JobStatusInProgress, now, q.policy.MaxAttempts,
+	).Scan(&jobs)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", result.Error)
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	return &jobs[0], nil
+}
+
+// reapExpired marks jobs dead whose claim expired on their last attempt.
+// Claim skips them, so otherwise they'd stay in progress, holding their
+// repository's place in the dedupe index, forever.
+func (q *PostgresQueue) reapExpired(ctx context.Context, now time.Time) error {
+	err := q.db.WithContext(ctx).Model(&domain.ScanJob{}).
+		Where("status = ? AND locked_until < ? AND attempts >= ?", domain.JobStatusInProgress, now, q.policy.MaxAttempts).
+		Updates(map[string]any{
+			"status":       domain.JobStatusDead,
+			"locked_until": nil,
+			"error":        "claim expired on the last attempt",
+			"updated_at":   now,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark expired jobs dead: %w", err)
+	}
+	return nil
+}
+
+// Extend pushes the job's claim out by another visibility timeout. The
+// attempt count tells this claim apart from a later one, in case the claim
+// already expired and another worker picked the job up.
+func (q *PostgresQueue) Extend(ctx context.Context, job *domain.ScanJob) error {
+	now := time.Now()
+	result := q.db.WithContext(ctx).Model(&domain.ScanJob{}).
+		Where("id = ? AND status = ? AND attempts = ?", job.ID, domain.JobStatusInProgress, job.Attempts).
+		Updates(map[string]any{"locked_until": now.Add(q.visibilityTimeout), "updated_at": now})
+	if result.Error != nil {
+		return fmt.Errorf("failed to extend claim on job %s: %w", job.ID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrClaimLost
+	}
+	return nil
+}
+
+func (q *PostgresQueue) Complete(ctx context.Context, job *domain.ScanJob) error {
+	return q.finish(ctx, job)
+}
+
+func (q *PostgresQueue) Fail(ctx context.Context, job *domain.ScanJob, cause error) error {
+	q.policy.apply(job, cause)
+	return q.finish(ctx, job)
+}
+
+// Requeue moves a dead job back to pending. It fails with ErrJobActive if
+// another job for the same repository has been queued in the meantime.
+func (q *PostgresQueue) Requeue(ctx context.Context, id string) (*domain.ScanJob, error) {
+	var job domain.ScanJob
+	if err := q.db.WithContext(ctx).First(&job, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to load job %s: %w", id, err)
+	}
+	if job.Status != domain.JobStatusDead {
+		return nil, ErrJobNotDead
+	}
+
+	var active int64
+	err := q.db.WithContext(ctx).Model(&domain.ScanJob{}).
+		Where("dedupe_key = ? AND status IN ?", job.DedupeKey, []domain.ScanJobStatus{domain.JobStatusPending, domain.JobStatusInProgress}).
+		Count(&active).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for active jobs: %w", err)
+	}
+	if active > 0 {
+		return nil, ErrJobActive
+	}
+
+	job.Status = domain.JobStatusPending
+	job.Attempts = 0
+	job.AvailableAt = nil
+	job.UpdatedAt = time.Now()
+
+	result := q.db.WithContext(ctx).Model(&job).
+		Where("status = ?", domain.JobStatusDead).
+		Select("status", "attempts", "available_at", "updated_at").Updates(&job)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to requeue job %s: %w", id, result.Error)
+	}
+	if result.RowsAffected == 0 { // requeued by someone else first
+		return nil, ErrJobNotDead
+	}
+	return &job, nil
+}
+
+func (q *PostgresQueue) DeadJobIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	err := q.db.WithContext(ctx).Model(&domain.ScanJob{}).
+		Where("status = ?", domain.JobStatusDead).
+		Order("created_at").Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead jobs: %w", err)
+	}
+	return ids, nil
+}
+
+// finish releases the claim on a job, taking it out of the dedupe index
+// unless it's going to be retried.
+// The attempt count makes sure the claim is still this worker's: if it
+// expired and the job was claimed again, the new claim is left alone.
+func (q *PostgresQueue) finish(ctx context.Context, job *domain.ScanJob) error {
+	job.LockedUntil = nil
+
+	result := q.db.WithContext(ctx).Model(job).
+		Where("status = ? AND attempts = ?", domain.JobStatusInProgress, job.Attempts).
+		Select("status", "updated_at", "locked_until", "available_at").Updates(job)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update job %s: %w", job.ID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrClaimLost
+	}
+	return nil
+}
@@ -1,17 +1,47 @@
 package queue
 
-import "openradar/internal/domain"
+import (
+	"context"
+	"errors"
 
-var JobQueue chan *domain.ScanJob
+	"openradar/internal/domain"
+)
 
-func NewInMemoryQueue(queueSize int) {
-	JobQueue = make(chan *domain.ScanJob, queueSize)
-}
+var (
+	ErrQueueFull   = errors.New("job queue full")
+	ErrJobNotDead  = errors.New("job is not dead")
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobActive   = errors.New("another job for this repository is already queued")
+	ErrClaimLost   = errors.New("claim on job expired and it was handed out again")
+)
 
-func Enqueue(job *domain.ScanJob) {
-	JobQueue <- job
+// Queue hands scan jobs from the event poller to the workers.
+type Queue interface {
+	// Enqueue adds a job unless one for the same repository (or push) is
+	// already waiting or running. It reports whether the job was added.
+	Enqueue(ctx context.Context, job *domain.ScanJob) (bool, error)
+	// Dequeue blocks until a job is available or ctx is done.
+	Dequeue(ctx context.Context) (*domain.ScanJob, error)
+	// Extend renews the claim on a running job, so a scan that takes longer
+	// than the visibility timeout isn't handed to another worker.
+	Extend(ctx context.Context, job *domain.ScanJob) error
+	// Complete releases a finished job.
+	Complete(ctx context.Context, job *domain.ScanJob) error
+	// Fail releases a failed job and either schedules another attempt or,
+	// once it's out of attempts, marks it dead. job.Status is updated to
+	// match. The caller persists the rest of the job.
+	Fail(ctx context.Context, job *domain.ScanJob, cause error) error
+	// Requeue gives a dead job a fresh set of attempts.
+	Requeue(ctx context.Context, id string) (*domain.ScanJob, error)
+	// DeadJobIDs lists every dead job.
+	DeadJobIDs(ctx context.Context) ([]string, error)
 }
 
-func Dequeue() *domain.ScanJob {
-	return <-JobQueue
+// dedupeKey is what makes two jobs the same. Pushes only cover their own
+// commits, so each push is its own job.
+func dedupeKey(job *domain.ScanJob) string {
+	if job.HeadSHA != "" {
+		return job.RepositoryURL + "@" + job.HeadSHA
+	}
+	return job.RepositoryURL
 }
@@ -0,0 +1,69 @@
+package queue
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"openradar/internal/domain"
+)
+
+// RetryPolicy decides what happens to a job after a failed attempt.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// permanentError marks a failure that retrying won't fix, like a deleted repo.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job fails without being retried.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+func IsPermanent(err error) bool {
+	var permanent permanentError
+	return errors.As(err, &permanent)
+}
+
+// Delay is the wait before attempt+1: BaseDelay doubled for every attempt so
+// far, capped at MaxDelay, with the upper half jittered so jobs that failed
+// together (say, during a GitHub outage) don't all retry together.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(half+1)
+}
+
+// apply moves a failed job on to its next status: back to pending after a
+// delay, dead once it's out of attempts, or failed if the error is permanent.
+func (p RetryPolicy) apply(job *domain.ScanJob, cause error) {
+	job.AvailableAt = nil
+	switch {
+	case IsPermanent(cause):
+		job.Status = domain.JobStatusFailed
+	case job.Attempts >= p.MaxAttempts:
+		job.Status = domain.JobStatusDead
+	default:
+		next := time.Now().Add(p.Delay(job.Attempts))
+		job.Status = domain.JobStatusPending
+		job.AvailableAt = &next
+	}
+}
@@ -7,14 +7,8 @@ import (
 
 var antKeyRegex = regexp.MustCompile(`sk-ant-[a-z0-9]{5,7}-[A-Za-z0-9_-]{90,110}`)
 
-func Anthropic(src string) (string, bool, string) {
-	key := antKeyRegex.FindString(src)
-	if key == "" { // no match
-		return "", false, "anthropic"
-	}
-	return key, true, "anthropic"
-}
+var Anthropic = NewRegexDetector("anthropic", antKeyRegex)
 
 func init() {
-	AllDetectors = append(AllDetectors, Anthropic)
+	Register(Anthropic)
 }
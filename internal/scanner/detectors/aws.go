@@ -0,0 +1,29 @@
+// AWS access keys, reported as ID:secret pairs
+package detectors
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Long-term (AKIA) and temporary (ASIA) access key IDs.
+var awsKeyIDRegex = regexp.MustCompile(`\b(?:AKIA|ASIA)[A-Z2-7]{16}\b`)
+
+// Secret access keys are 40 characters of base64, so the characters either
+// side mustn't be base64 too.
+var awsSecretRegex = regexp.MustCompile(`(?:^|[^A-Za-z0-9/+])([A-Za-z0-9/+]{40})(?:[^A-Za-z0-9/+=]|$)`)
+
+const awsSecretMinEntropy = 4.0 // rules out hashes and other hex strings
+
+var AWS Detector = &pairDetector{
+	provider: "aws",
+	id:       awsKeyIDRegex,
+	secret:   awsSecretRegex,
+	valid: func(secret string) bool {
+		return ShannonEntropy(secret) >= awsSecretMinEntropy && strings.ContainsAny(secret, "abcdefghijklmnopqrstuvwxyz")
+	},
+}
+
+func init() {
+	Register(AWS)
+}
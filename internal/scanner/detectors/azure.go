@@ -0,0 +1,20 @@
+// Azure Storage and Service Bus connection strings
+package detectors
+
+import (
+	"regexp"
+)
+
+// The whole connection string is the key: the account name or endpoint is
+// what makes the key usable. Storage account keys are 64 bytes of base64,
+// Service Bus shared access keys 32.
+var azureConnectionStringRegex = regexp.MustCompile(
+	`(?:DefaultEndpointsProtocol=https?;)?AccountName=[a-z0-9]{3,24};AccountKey=[A-Za-z0-9+/]{86}==(?:;EndpointSuffix=[a-z0-9.]+)?` +
+		`|Endpoint=sb://[a-z0-9-]+\.servicebus\.windows\.net/?;SharedAccessKeyName=[^;\s"']+;SharedAccessKey=[A-Za-z0-9+/]{43}=(?:;EntityPath=[^;\s"']+)?`,
+)
+
+var Azure = NewRegexDetector("azure", azureConnectionStringRegex)
+
+func init() {
+	Register(Azure)
+}
@@ -1,40 +1,250 @@
 package detectors
 
-import "strings"
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
 
-type DetectorFunc func(src string) (string, bool, string)
+// Match.Confidence values
+const (
+	ConfidenceHigh = "high" // the key's format identifies the provider
+	ConfidenceLow  = "low"  // only the surrounding code suggests it's a secret
+)
 
-var AllDetectors []DetectorFunc
+// Match is a single secret found in a source file.
+type Match struct {
+	Key      string `json:"key"`
+	Provider string `json:"provider"`
+	Offset   int    `json:"offset"`  // byte offset of the key in the source
+	Line     int    `json:"line"`    // 1-based
+	Column   int    `json:"column"`  // 1-based, in bytes
+	Snippet  string `json:"snippet"` // surrounding lines, key redacted
 
-func EnsureKeyIsntSpam(key string) bool {
-	lower := strings.ToLower(key)
-	if strings.Count(lower, "x") >= 6 {
-		return false // spam
+	Confidence string `json:"confidence"` // ConfidenceHigh unless the detector says otherwise
+
+	// Composite keys (an AWS key ID and its secret, say) aren't one span of
+	// the source. End is where the last part ends, and Parts are the pieces
+	// as they appear in the source, so snippets can redact each one.
+	End   int      `json:"-"` // 0 = Offset + len(Key)
+	Parts []string `json:"-"`
+
+	// Where End is, filled in by FindAll. EndColumn is the column just after
+	// the match, as SARIF wants it.
+	EndLine   int `json:"-"`
+	EndColumn int `json:"-"`
+
+	// Why the match was suppressed, if it was; see suppress.go
+	Suppressed string `json:"suppressed,omitempty"`
+}
+
+// Detector finds every secret of one provider in a source file.
+type Detector interface {
+	Provider() string
+	FindAll(src string) []Match
+}
+
+var AllDetectors []Detector
+
+func Register(detector Detector) {
+	AllDetectors = append(AllDetectors, detector)
+}
+
+// Unregister removes every detector for provider.
+func Unregister(provider string) {
+	kept := AllDetectors[:0]
+	for _, detector := range AllDetectors {
+		if detector.Provider() != provider {
+			kept = append(kept, detector)
+		}
+	}
+	AllDetectors = kept
+}
+
+// Providers lists the providers of every registered detector, sorted.
+func Providers() []string {
+	seen := make(map[string]bool, len(AllDetectors))
+	providers := make([]string, 0, len(AllDetectors))
+	for _, detector := range AllDetectors {
+		if provider := detector.Provider(); !seen[provider] {
+			seen[provider] = true
+			providers = append(providers, provider)
+		}
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// FindAll runs every registered detector over src. Low confidence matches
+// that overlap a high confidence one are dropped, so OPENAI_API_KEY="sk-..."
+// is only reported as an OpenAI key. A private key inside a GCP service
+// account is only reported as the service account.
+func FindAll(src string) []Match {
+	var matches []Match
+	for _, detector := range AllDetectors {
+		for _, match := range detector.FindAll(src) {
+			if match.Confidence == "" {
+				match.Confidence = ConfidenceHigh
+			}
+			matches = append(matches, match)
+		}
+	}
+	snippets := newSnippets(src, matches)
+	matches = dropOverlapping(matches)
+	for i := range matches {
+		matches[i].EndLine, matches[i].EndColumn = snippets.position(matches[i].end())
+		matches[i].Snippet = snippets.snippet(matches[i])
+		if allowedInline(src, matches[i].Offset) {
+			matches[i].Suppressed = SuppressedInline
+		}
 	}
-	if strings.Contains(lower, "your_api_key") || strings.Contains(lower, "placeholder") {
-		return false // spam
+	return matches
+}
+
+// regexDetector reports every match of a single regex.
+type regexDetector struct {
+	provider string
+	regex    *regexp.Regexp
+	valid    func(key string) bool // nil = every match is valid
+}
+
+func NewRegexDetector(provider string, regex *regexp.Regexp) Detector {
+	return &regexDetector{provider: provider, regex: regex}
+}
+
+// NewCheckedRegexDetector only reports matches that valid accepts, for key
+// formats with a checksum or other structure a regex can't check.
+func NewCheckedRegexDetector(provider string, regex *regexp.Regexp, valid func(key string) bool) Detector {
+	return &regexDetector{provider: provider, regex: regex, valid: valid}
+}
+
+func (d *regexDetector) Provider() string {
+	return d.provider
+}
+
+func (d *regexDetector) FindAll(src string) []Match {
+	locs := d.regex.FindAllStringIndex(src, -1)
+	if locs == nil { // no match
+		return nil
 	}
-	if strings.Contains(lower, "abcdefg") {
-		return false // spam
+
+	matches := make([]Match, 0, len(locs))
+	pos := NewPositioner(src)
+	for _, loc := range locs {
+		key := src[loc[0]:loc[1]]
+		if d.valid != nil && !d.valid(key) {
+			continue
+		}
+		line, column := pos.At(loc[0])
+		matches = append(matches, Match{
+			Key:      key,
+			Provider: d.provider,
+			Offset:   loc[0],
+			Line:     line,
+			Column:   column,
+		})
 	}
-	if strings.Contains(lower, "12345678") {
-		return false // spam
+	return matches
+}
+
+// Positioner turns byte offsets into line/column pairs. It is cheapest when
+// offsets are asked for in increasing order, which is how regex matches come
+// back.
+type Positioner struct {
+	src       string
+	offset    int
+	line      int
+	lineStart int
+}
+
+func NewPositioner(src string) *Positioner {
+	return &Positioner{src: src, line: 1}
+}
+
+func (p *Positioner) At(offset int) (int, int) {
+	if offset < p.offset {
+		p.offset, p.line, p.lineStart = 0, 1, 0
 	}
-	if strings.Contains(lower, "123") {
-		return false //spam
+	for {
+		i := strings.IndexByte(p.src[p.offset:offset], '\n')
+		if i < 0 {
+			break
+		}
+		p.offset += i + 1
+		p.line++
+		p.lineStart = p.offset
 	}
-	if strings.Contains(lower, "abc") {
-		return false
+	p.offset = offset
+	return p.line, offset - p.lineStart + 1
+}
+
+// dropOverlapping sweeps the matches in offset order, so it costs the same
+// however many of them a file holds. Whatever starts at or before a match is
+// summed up by how far it reaches, and the only later match that can
+// overlap it is the next confident one. The kept matches stay in the order
+// the detectors found them.
+func dropOverlapping(matches []Match) []Match {
+	order := make([]int, len(matches))
+	for i := range order {
+		order[i] = i
 	}
-	if strings.Contains(lower, "test") {
-		return false
+	// a container starting where its key does has to come first
+	sort.SliceStable(order, func(a, b int) bool {
+		ma, mb := matches[order[a]], matches[order[b]]
+		if ma.Offset != mb.Offset {
+			return ma.Offset < mb.Offset
+		}
+		return ma.end() > mb.end()
+	})
+
+	nextConfident := make([]int, len(order)) // where the next confident match starts
+	next := math.MaxInt
+	for i := len(order) - 1; i >= 0; i-- {
+		nextConfident[i] = next
+		if match := matches[order[i]]; match.Confidence != ConfidenceLow {
+			next = match.Offset
+		}
 	}
-	if strings.Contains(lower, "token") {
-		return false
+
+	drop := make([]bool, len(matches))
+	confidentReach := -1
+	containerReach := make(map[string]int) // by provider, from End as containers set it
+	for i, idx := range order {
+		match := matches[idx]
+		if match.Confidence == ConfidenceLow && (confidentReach > match.Offset || nextConfident[i] < match.end()) {
+			drop[idx] = true
+		}
+		if container, ok := containers[match.Provider]; ok && match.end() <= containerReach[container] {
+			drop[idx] = true
+		}
+
+		if match.Confidence != ConfidenceLow {
+			confidentReach = max(confidentReach, match.end())
+			containerReach[match.Provider] = max(containerReach[match.Provider], match.End)
+		}
 	}
-	if strings.Contains(lower, "000000") {
-		return false
+
+	kept := make([]Match, 0, len(matches))
+	for i, match := range matches {
+		if !drop[i] {
+			kept = append(kept, match)
+		}
 	}
+	return kept
+}
 
-	return true // not spam
+// containers maps a provider to the provider whose findings contain its
+// keys as part of a bigger credential. Other matches inside a composite
+// span, like a token between an AWS key ID and its secret, are unrelated
+// and kept.
+var containers = map[string]string{
+	"privatekey": "gcp",
+}
+
+func (m Match) end() int {
+	if m.End > 0 {
+		return m.End
+	}
+	return m.Offset + len(m.Key)
 }
@@ -0,0 +1,96 @@
+// GCP service account keys: the JSON file you download from the console
+package detectors
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+var gcpTypeRegex = regexp.MustCompile(`"type"\s*:\s*"service_account"`)
+
+type gcpServiceAccount struct {
+	Type         string `json:"type"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+}
+
+type gcpDetector struct{}
+
+var GCP Detector = gcpDetector{}
+
+func init() {
+	Register(GCP)
+}
+
+func (gcpDetector) Provider() string {
+	return "gcp"
+}
+
+// FindAll parses the object around every "type": "service_account". The
+// key is client_email:private_key_id, which identifies the credential
+// without storing the private key.
+func (d gcpDetector) FindAll(src string) []Match {
+	locs := gcpTypeRegex.FindAllStringIndex(src, -1)
+	if locs == nil { // no match
+		return nil
+	}
+
+	var matches []Match
+	pos := NewPositioner(src)
+	end := 0
+	for _, loc := range locs {
+		if loc[0] < end { // inside an object already reported
+			continue
+		}
+		start := enclosingBrace(src, loc[0])
+		if start < 0 {
+			continue
+		}
+
+		var account gcpServiceAccount
+		decoder := json.NewDecoder(strings.NewReader(src[start:]))
+		if err := decoder.Decode(&account); err != nil {
+			continue
+		}
+		if account.Type != "service_account" || account.ClientEmail == "" ||
+			!strings.Contains(account.PrivateKey, "PRIVATE KEY") {
+			continue
+		}
+		end = start + int(decoder.InputOffset())
+
+		// the private key as it's written in the source, newlines escaped
+		rawKey, _ := json.Marshal(account.PrivateKey)
+		line, column := pos.At(start)
+		matches = append(matches, Match{
+			Key:      account.ClientEmail + ":" + account.PrivateKeyID,
+			Provider: d.Provider(),
+			Offset:   start,
+			Line:     line,
+			Column:   column,
+			End:      end,
+			Parts:    []string{strings.Trim(string(rawKey), `"`), account.PrivateKeyID},
+		})
+	}
+	return matches
+}
+
+// enclosingBrace finds the { that opens the object containing offset, or -1.
+// Braces inside strings aren't accounted for; service account files don't
+// have any.
+func enclosingBrace(src string, offset int) int {
+	depth := 0
+	for i := offset - 1; i >= 0; i-- {
+		switch src[i] {
+		case '}':
+			depth++
+		case '{':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
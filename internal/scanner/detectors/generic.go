@@ -0,0 +1,115 @@
+// Secrets with no recognisable prefix, found by what they're assigned to:
+// password = "...", API_SECRET=..., client_secret: ...
+package detectors
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DefaultGenericKeywords are matched anywhere in an assignment's name,
+// ignoring case, so "secret" covers CLIENT_SECRET and secretKey.
+var DefaultGenericKeywords = []string{
+	"password", "passwd", "pwd", "secret", "token", "api_key", "apikey",
+	"api-key", "access_key", "accesskey", "auth_key", "credential", "private_key",
+}
+
+const DefaultGenericMinEntropy = 3.5
+
+// genericValue is what's assigned: quoted or bare, up to the next quote,
+// whitespace or separator, and long enough to be a secret.
+const genericValue = `["'` + "`" + `]?([^\s"'` + "`" + `;,]{8,200})`
+
+// genericSkip are values that refer to a secret rather than being one:
+// ${VAR}, $VAR, %VAR%, <placeholder>, process.env.X and function calls.
+var genericSkip = regexp.MustCompile(`^[$%<{]|[()]|^(?:process\.env|os\.environ|env|config|settings|self|this)\.`)
+
+type genericDetector struct {
+	mu         sync.RWMutex
+	regex      *regexp.Regexp
+	keywords   []string
+	minEntropy float64
+}
+
+var Generic = &genericDetector{}
+
+func init() {
+	if err := Generic.Configure(DefaultGenericKeywords, DefaultGenericMinEntropy); err != nil {
+		panic(err)
+	}
+	Register(Generic)
+}
+
+// Configure replaces the keyword list and the entropy a value needs.
+func (d *genericDetector) Configure(keywords []string, minEntropy float64) error {
+	if len(keywords) == 0 {
+		return fmt.Errorf("generic detector needs at least one keyword")
+	}
+	quoted := make([]string, 0, len(keywords))
+	lower := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		quoted = append(quoted, regexp.QuoteMeta(keyword))
+		lower = append(lower, strings.ToLower(keyword))
+	}
+
+	// name, optionally quoted, then =, :=, : or =>, then the value
+	regex, err := regexp.Compile(`(?i)[\w.-]*(?:` + strings.Join(quoted, "|") + `)[\w.-]*["']?\s*(?::=|=>|=|:)\s*` + genericValue)
+	if err != nil {
+		return fmt.Errorf("invalid generic keywords: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.regex = regex
+	d.keywords = lower
+	d.minEntropy = minEntropy
+	return nil
+}
+
+func (d *genericDetector) Provider() string {
+	return "generic"
+}
+
+func (d *genericDetector) FindAll(src string) []Match {
+	d.mu.RLock()
+	regex, keywords, minEntropy := d.regex, d.keywords, d.minEntropy
+	d.mu.RUnlock()
+
+	// Same cheap prefilter as rules: skip files without any keyword.
+	lower := strings.ToLower(src)
+	if !containsAny(lower, keywords) {
+		return nil
+	}
+
+	var matches []Match
+	pos := NewPositioner(src)
+	for _, loc := range regex.FindAllStringSubmatchIndex(src, -1) {
+		start, end := loc[2], loc[3]
+		value := src[start:end]
+		if genericSkip.MatchString(value) || ShannonEntropy(value) < minEntropy {
+			continue
+		}
+
+		line, column := pos.At(start)
+		matches = append(matches, Match{
+			Key:        value,
+			Provider:   d.Provider(),
+			Offset:     start,
+			Line:       line,
+			Column:     column,
+			Confidence: ConfidenceLow,
+		})
+	}
+	return matches
+}
+
+func containsAny(s string, words []string) bool {
+	for _, word := range words {
+		if strings.Contains(s, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+// GitHub tokens: classic personal access (ghp_), OAuth (gho_), user-to-server
+// (ghu_), server-to-server (ghs_) and refresh (ghr_) tokens, and fine-grained
+// personal access tokens (github_pat_)
+package detectors
+
+import (
+	"hash/crc32"
+	"regexp"
+	"strings"
+)
+
+var githubKeyRegex = regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9]{22}_[A-Za-z0-9]{59})\b`)
+
+var GitHub = NewCheckedRegexDetector("github", githubKeyRegex, githubChecksumValid)
+
+func init() {
+	Register(GitHub)
+}
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// githubChecksumValid checks a classic token's last 6 characters, which are
+// the CRC32 of the 30 before them in base62. Random lookalikes pass about
+// once in 4 billion. Fine-grained tokens' checksum isn't documented, so
+// they're always accepted.
+func githubChecksumValid(key string) bool {
+	if strings.HasPrefix(key, "github_pat_") {
+		return true
+	}
+	body := key[len("ghp_"):]
+	random, checksum := body[:30], body[30:]
+	return base62(crc32.ChecksumIEEE([]byte(random)), len(checksum)) == checksum
+}
+
+// base62 encodes n, zero-padded to width.
+func base62(n uint32, width int) string {
+	encoded := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		encoded[i] = base62Alphabet[n%62]
+		n /= 62
+	}
+	return string(encoded)
+}
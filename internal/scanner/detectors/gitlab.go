@@ -0,0 +1,16 @@
+// GitLab personal, project and group access tokens
+package detectors
+
+import (
+	"regexp"
+)
+
+// 20 characters, or longer for the newer routable tokens, which end in a
+// version and a base36 suffix.
+var gitlabKeyRegex = regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}(?:\.[0-9a-z]{2}\.[0-9a-z]{9})?\b`)
+
+var GitLab = NewRegexDetector("gitlab", gitlabKeyRegex)
+
+func init() {
+	Register(GitLab)
+}
@@ -7,14 +7,8 @@ import (
 
 var googleAPIRegex = regexp.MustCompile(`AIzaSy[A-Za-z0-9_-]{33,}`)
 
-func Google(src string) (string, bool, string) {
-	key := googleAPIRegex.FindString(src)
-	if key == "" {
-		return "", false, "google"
-	}
-	return key, true, "google"
-}
+var Google = NewRegexDetector("google", googleAPIRegex)
 
 func init() {
-	AllDetectors = append(AllDetectors, Google)
+	Register(Google)
 }
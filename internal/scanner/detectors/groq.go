@@ -7,14 +7,8 @@ import (
 
 var gskKeyRegex = regexp.MustCompile(`gsk_[A-Za-z0-9]{32,56}`)
 
-func Groq(src string) (string, bool, string) {
-	key := gskKeyRegex.FindString(src)
-	if key == "" { // no match
-		return "", false, "groq"
-	}
-	return key, true, "groq"
-}
+var Groq = NewRegexDetector("groq", gskKeyRegex)
 
 func init() {
-	AllDetectors = append(AllDetectors, Groq)
+	Register(Groq)
 }
@@ -0,0 +1,14 @@
+// Mailgun api keys
+package detectors
+
+import (
+	"regexp"
+)
+
+var mailgunKeyRegex = regexp.MustCompile(`\bkey-[a-f0-9]{32}\b`)
+
+var Mailgun = NewRegexDetector("mailgun", mailgunKeyRegex)
+
+func init() {
+	Register(Mailgun)
+}
@@ -0,0 +1,14 @@
+// npm access tokens
+package detectors
+
+import (
+	"regexp"
+)
+
+var npmKeyRegex = regexp.MustCompile(`\bnpm_[A-Za-z0-9]{36}\b`)
+
+var Npm = NewRegexDetector("npm", npmKeyRegex)
+
+func init() {
+	Register(Npm)
+}
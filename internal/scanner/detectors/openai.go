@@ -0,0 +1,18 @@
+// openai api keys
+package detectors
+
+import (
+	"regexp"
+)
+
+// Every OpenAI key has "T3BlbkFJ" (base64 of "OpenAI") in the middle, which
+// keeps the legacy sk- format from matching Anthropic (sk-ant-) or
+// OpenRouter (sk-or-) keys. Project, service account and admin keys have a
+// type segment; legacy user keys are sk- and 20 characters either side.
+var openaiKeyRegex = regexp.MustCompile(`\bsk-(?:(?:proj|svcacct|admin)-[A-Za-z0-9_-]{20,200}T3BlbkFJ[A-Za-z0-9_-]{20,200}|[A-Za-z0-9]{20}T3BlbkFJ[A-Za-z0-9]{20}\b)`)
+
+var OpenAI = NewRegexDetector("openai", openaiKeyRegex)
+
+func init() {
+	Register(OpenAI)
+}
@@ -0,0 +1,76 @@
+package detectors
+
+import (
+	"regexp"
+	"strings"
+)
+
+const pairMaxLines = 5 // a secret further from its ID than this isn't paired with it
+
+// pairDetector reports credentials made of an ID and a secret, neither of
+// which is any use alone. Each ID is paired with the nearest secret, and an
+// ID with no secret nearby isn't reported. The key is ID:secret.
+type pairDetector struct {
+	provider string
+	id       *regexp.Regexp
+	secret   *regexp.Regexp           // the secret is the first submatch
+	valid    func(secret string) bool // nil = every secret is valid
+}
+
+func (d *pairDetector) Provider() string {
+	return d.provider
+}
+
+func (d *pairDetector) FindAll(src string) []Match {
+	ids := d.id.FindAllStringIndex(src, -1)
+	if ids == nil { // no match
+		return nil
+	}
+
+	var secrets [][]int
+	for _, loc := range d.secret.FindAllStringSubmatchIndex(src, -1) {
+		if d.valid == nil || d.valid(src[loc[2]:loc[3]]) {
+			secrets = append(secrets, loc[2:4])
+		}
+	}
+
+	var matches []Match
+	pos := NewPositioner(src)
+	for _, id := range ids {
+		secret, ok := nearest(src, id, secrets)
+		if !ok {
+			continue
+		}
+
+		start, end := min(id[0], secret[0]), max(id[1], secret[1])
+		idKey, secretKey := src[id[0]:id[1]], src[secret[0]:secret[1]]
+		line, column := pos.At(start)
+		matches = append(matches, Match{
+			Key:      idKey + ":" + secretKey,
+			Provider: d.provider,
+			Offset:   start,
+			Line:     line,
+			Column:   column,
+			End:      end,
+			Parts:    []string{idKey, secretKey},
+		})
+	}
+	return matches
+}
+
+// nearest finds the candidate closest to loc within pairMaxLines lines.
+func nearest(src string, loc []int, candidates [][]int) ([]int, bool) {
+	var best []int
+	bestDistance := -1
+	for _, candidate := range candidates {
+		lo, hi := min(loc[0], candidate[0]), max(loc[1], candidate[1])
+		if strings.Count(src[lo:hi], "\n") > pairMaxLines {
+			continue
+		}
+		distance := hi - lo
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best, bestDistance >= 0
+}
@@ -0,0 +1,348 @@
+// Private keys: PEM (PKCS#1, PKCS#8, SEC 1, DSA), OpenSSH and PGP blocks,
+// and base64 encoded PKCS#12 bundles
+package detectors
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/pkcs12"
+	"golang.org/x/crypto/ssh"
+)
+
+var privateKeyBeginRegex = regexp.MustCompile(`-----BEGIN ((?:RSA |EC |DSA |OPENSSH |ENCRYPTED )?PRIVATE KEY|PGP PRIVATE KEY BLOCK)-----`)
+
+// PKCS#12 files are binary, so they only turn up in text base64 encoded on
+// one line, in Kubernetes secrets or environment variables. DER starts 0x30
+// 0x82, which is MII in base64.
+var pkcs12Regex = regexp.MustCompile(`\bMII[A-Za-z0-9+/]{600,}={0,2}`)
+
+const maxPrivateKeyBlock = 64 << 10 // give up looking for the END line after this
+
+type privateKeyDetector struct{}
+
+var PrivateKey Detector = privateKeyDetector{}
+
+func init() {
+	Register(PrivateKey)
+}
+
+func (privateKeyDetector) Provider() string {
+	return "privatekey"
+}
+
+// FindAll reports every block that parses as a private key. The key is the
+// SHA-256 fingerprint of its public key, so findings don't store the private
+// key. Encrypted keys are low confidence: they're only usable with the
+// passphrase.
+func (d privateKeyDetector) FindAll(src string) []Match {
+	var matches []Match
+	pos := NewPositioner(src)
+	end := 0
+	for _, loc := range privateKeyBeginRegex.FindAllStringSubmatchIndex(src, -1) {
+		if loc[0] < end { // inside a block already reported
+			continue
+		}
+		label := src[loc[2]:loc[3]]
+		footer := "-----END " + label + "-----"
+		i := strings.Index(src[loc[1]:min(len(src), loc[1]+maxPrivateKeyBlock)], footer)
+		if i < 0 {
+			continue
+		}
+		blockEnd := loc[1] + i + len(footer)
+		block := src[loc[0]:blockEnd]
+
+		lines := blockLines(block)
+		fingerprint, encrypted, ok := parsePrivateKey(label, []byte(strings.Join(lines, "\n")))
+		if !ok {
+			continue
+		}
+		end = blockEnd
+
+		line, column := pos.At(loc[0])
+		matches = append(matches, d.match(fingerprint, encrypted, loc[0], line, column, blockEnd, lines))
+	}
+
+	for _, loc := range pkcs12Regex.FindAllStringIndex(src, -1) {
+		blob := src[loc[0]:loc[1]]
+		fingerprint, encrypted, ok := parsePKCS12(blob)
+		if !ok {
+			continue
+		}
+		line, column := pos.At(loc[0])
+		matches = append(matches, d.match(fingerprint, encrypted, loc[0], line, column, loc[1], []string{blob}))
+	}
+	return matches
+}
+
+func (d privateKeyDetector) match(fingerprint string, encrypted bool, offset int, line int, column int, end int, lines []string) Match {
+	match := Match{
+		Key:        fingerprint,
+		Provider:   d.Provider(),
+		Offset:     offset,
+		Line:       line,
+		Column:     column,
+		End:        end,
+		Confidence: ConfidenceHigh,
+	}
+	// redact the key material, but not the BEGIN and END lines, so snippets
+	// still show what was found
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "-----") {
+			match.Parts = append(match.Parts, line)
+		}
+	}
+	if encrypted {
+		match.Confidence = ConfidenceLow
+	}
+	return match
+}
+
+// blockLines splits a block into trimmed lines. Blocks in JSON or on one
+// line of code have their newlines escaped as \n.
+func blockLines(block string) []string {
+	if !strings.Contains(block, "\n") {
+		block = strings.ReplaceAll(block, `\n`, "\n")
+	}
+	var lines []string
+	for _, line := range strings.Split(block, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// parsePrivateKey parses a block and fingerprints its public key.
+func parsePrivateKey(label string, data []byte) (fingerprint string, encrypted bool, ok bool) {
+	switch label {
+	case "PGP PRIVATE KEY BLOCK":
+		return parsePGPKey(data)
+	case "ENCRYPTED PRIVATE KEY":
+		// PKCS#8 EncryptedPrivateKeyInfo. The public key is encrypted along
+		// with the private key, so the block itself is fingerprinted.
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return "", false, false
+		}
+		var info struct {
+			Algorithm     pkix.AlgorithmIdentifier
+			EncryptedData []byte
+		}
+		if rest, err := asn1.Unmarshal(block.Bytes, &info); err != nil || len(rest) > 0 {
+			return "", false, false
+		}
+		return sha256Fingerprint(block.Bytes), true, true
+	}
+
+	key, err := ssh.ParseRawPrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if missing.PublicKey != nil { // OpenSSH keeps the public key in the clear
+			return ssh.FingerprintSHA256(missing.PublicKey), true, true
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return "", false, false
+		}
+		return sha256Fingerprint(block.Bytes), true, true
+	}
+	if err != nil {
+		return "", false, false
+	}
+	return publicKeyFingerprint(key)
+}
+
+func publicKeyFingerprint(key any) (string, bool, bool) {
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return "", false, false
+	}
+	return ssh.FingerprintSHA256(signer.PublicKey()), false, true
+}
+
+// parsePKCS12 tries a PKCS#12 bundle with an empty password. Bundles with a
+// password, or with newer algorithms than the pkcs12 package handles, are
+// reported as encrypted, fingerprinted by the bundle itself.
+func parsePKCS12(blob string) (string, bool, bool) {
+	der, err := base64.StdEncoding.DecodeString(blob)
+	if err != nil {
+		return "", false, false
+	}
+	blocks, err := pkcs12.ToPEM(der, "")
+	var unsupported pkcs12.NotImplementedError
+	if errors.Is(err, pkcs12.ErrIncorrectPassword) || errors.As(err, &unsupported) {
+		return sha256Fingerprint(der), true, true
+	}
+	if err != nil {
+		return "", false, false
+	}
+	for _, block := range blocks {
+		if block.Type != "PRIVATE KEY" {
+			continue
+		}
+		// ToPEM labels every key PRIVATE KEY, but writes RSA keys as PKCS#1
+		// and EC keys as SEC 1
+		if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+			return publicKeyFingerprint(key)
+		}
+		if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+			return publicKeyFingerprint(key)
+		}
+		if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+			return publicKeyFingerprint(key)
+		}
+	}
+	return "", false, false // certificates only
+}
+
+func sha256Fingerprint(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// parsePGPKey reads the first packet of an armored PGP private key, which
+// is the primary secret key: the public key, then whether the secret part
+// is encrypted. Only version 4 keys, which is what gpg writes, are parsed.
+func parsePGPKey(armored []byte) (string, bool, bool) {
+	data, ok := dearmor(armored)
+	if !ok {
+		return "", false, false
+	}
+	tag, body, ok := pgpPacket(data)
+	if !ok || tag != pgpSecretKeyTag {
+		return "", false, false
+	}
+	n, ok := pgpPublicKeyLen(body)
+	if !ok || n >= len(body) {
+		return "", false, false
+	}
+	// the same bytes a v4 fingerprint hashes, with SHA-256 instead of SHA-1
+	public := append([]byte{0x99, byte(n >> 8), byte(n)}, body[:n]...)
+	encrypted := body[n] != 0 // S2K usage: 0 = stored in the clear
+	return sha256Fingerprint(public), encrypted, true
+}
+
+// dearmor decodes an ASCII armored block: BEGIN line, headers, a blank line,
+// base64, then a CRC24 line starting with = and the END line.
+func dearmor(armored []byte) ([]byte, bool) {
+	lines := strings.Split(string(armored), "\n")
+	if len(lines) < 3 {
+		return nil, false
+	}
+	lines = lines[1 : len(lines)-1] // BEGIN and END
+	for i, line := range lines {
+		if !strings.Contains(line, ": ") { // end of headers; blockLines dropped the blank line
+			lines = lines[i:]
+			break
+		}
+	}
+	var body bytes.Buffer
+	for _, line := range lines {
+		if strings.HasPrefix(line, "=") {
+			break
+		}
+		body.WriteString(line)
+	}
+	data, err := base64.StdEncoding.DecodeString(body.String())
+	return data, err == nil && len(data) > 0
+}
+
+const pgpSecretKeyTag = 5
+
+// pgpPacket splits the first packet off data, in either header format.
+func pgpPacket(data []byte) (tag byte, body []byte, ok bool) {
+	if len(data) < 2 || data[0]&0x80 == 0 {
+		return 0, nil, false
+	}
+	var length, header int
+	if data[0]&0x40 != 0 { // new format
+		tag = data[0] & 0x3f
+		switch first := int(data[1]); {
+		case first < 192:
+			length, header = first, 2
+		case first < 224 && len(data) >= 3:
+			length, header = (first-192)<<8+int(data[2])+192, 3
+		case first == 255 && len(data) >= 6:
+			length, header = int(data[2])<<24|int(data[3])<<16|int(data[4])<<8|int(data[5]), 6
+		default: // partial lengths aren't used for keys
+			return 0, nil, false
+		}
+	} else { // old format
+		tag = (data[0] >> 2) & 0x0f
+		switch data[0] & 3 {
+		case 0:
+			length, header = int(data[1]), 2
+		case 1:
+			if len(data) < 3 {
+				return 0, nil, false
+			}
+			length, header = int(data[1])<<8|int(data[2]), 3
+		case 2:
+			if len(data) < 5 {
+				return 0, nil, false
+			}
+			length, header = int(data[1])<<24|int(data[2])<<16|int(data[3])<<8|int(data[4]), 5
+		default:
+			length, header = len(data)-1, 1
+		}
+	}
+	if length < 0 || header+length > len(data) {
+		return 0, nil, false
+	}
+	return tag, data[header : header+length], true
+}
+
+// pgpPublicKeyLen returns how many bytes of a v4 key packet are the public
+// key: version, creation time, algorithm, then the algorithm's fields.
+func pgpPublicKeyLen(body []byte) (int, bool) {
+	if len(body) < 6 || body[0] != 4 {
+		return 0, false
+	}
+	var mpis int
+	var oid, kdf bool
+	switch body[5] {
+	case 1, 2, 3: // RSA: n, e
+		mpis = 2
+	case 16: // Elgamal: p, g, y
+		mpis = 3
+	case 17: // DSA: p, q, g, y
+		mpis = 4
+	case 18: // ECDH: curve, point, KDF parameters
+		oid, mpis, kdf = true, 1, true
+	case 19, 22: // ECDSA, EdDSA: curve, point
+		oid, mpis = true, 1
+	default:
+		return 0, false
+	}
+
+	n := 6
+	if oid {
+		if n >= len(body) {
+			return 0, false
+		}
+		n += 1 + int(body[n])
+	}
+	for range mpis {
+		if n+2 > len(body) {
+			return 0, false
+		}
+		bits := int(body[n])<<8 | int(body[n+1])
+		n += 2 + (bits+7)/8
+	}
+	if kdf {
+		if n >= len(body) {
+			return 0, false
+		}
+		n += 1 + int(body[n])
+	}
+	return n, n <= len(body)
+}
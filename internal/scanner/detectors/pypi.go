@@ -0,0 +1,16 @@
+// PyPI and TestPyPI API tokens
+package detectors
+
+import (
+	"regexp"
+)
+
+// Tokens are macaroons whose header names the issuing index: base64 of
+// "pypi.org" or "test.pypi.org".
+var pypiKeyRegex = regexp.MustCompile(`\bpypi-(?:AgEIcHlwaS5vcmc|AgENdGVzdC5weXBpLm9yZw)[A-Za-z0-9_-]{50,}`)
+
+var PyPI = NewRegexDetector("pypi", pypiKeyRegex)
+
+func init() {
+	Register(PyPI)
+}
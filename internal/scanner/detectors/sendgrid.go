@@ -0,0 +1,15 @@
+// SendGrid api keys
+package detectors
+
+import (
+	"regexp"
+)
+
+// SG. then the key ID and the secret, both base64url.
+var sendgridKeyRegex = regexp.MustCompile(`\bSG\.[A-Za-z0-9_-]{22}\.[A-Za-z0-9_-]{43}`)
+
+var SendGrid = NewRegexDetector("sendgrid", sendgridKeyRegex)
+
+func init() {
+	Register(SendGrid)
+}
@@ -7,14 +7,8 @@ import (
 
 var slackKeyRegex = regexp.MustCompile(`xox[bpaedcs]-[A-Za-z0-9-]{10,72}`)
 
-func Slack(src string) (string, bool, string) {
-	key := slackKeyRegex.FindString(src)
-	if key == "" { // no match
-		return "", false, "slack"
-	}
-	return key, true, "slack"
-}
+var Slack = NewRegexDetector("slack", slackKeyRegex)
 
 func init() {
-	AllDetectors = append(AllDetectors, Slack)
+	Register(Slack)
 }
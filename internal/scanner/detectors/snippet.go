@@ -0,0 +1,155 @@
+package detectors
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	snippetContext  = 2   // lines either side of the match
+	maxSnippetLine  = 200 // bytes per line, so minified files don't blow up
+	redactKeepRatio = 4   // show at most 1/4 of the key
+	redactKeepMax   = 8
+)
+
+// Redact keeps enough of a key to recognise its provider and masks the rest.
+func Redact(key string) string {
+	keep := redactKeep(len(key))
+	return key[:keep] + strings.Repeat("*", len(key)-keep)
+}
+
+func redactKeep(n int) int {
+	return min(n/redactKeepRatio, redactKeepMax)
+}
+
+// Snippet returns the lines around a match with the key redacted, along with
+// the keys of every other match in src, which may sit on the context lines.
+func Snippet(src string, match Match, matches []Match) string {
+	return newSnippets(src, append([]Match{match}, matches...)).snippet(match)
+}
+
+// snippets builds the snippets for the matches in one file. The file is split
+// into lines once, and keys are redacted by where they are in it, so a
+// snippet costs the same however many keys the file holds.
+type snippets struct {
+	src        string
+	lineStarts []int
+	spans      []span // every key in the file, by start
+	maxEnd     []int  // maxEnd[i] is the furthest any of spans[:i+1] reaches
+}
+
+type span struct {
+	start, end int
+}
+
+func newSnippets(src string, matches []Match) *snippets {
+	s := &snippets{src: src, lineStarts: []int{0}}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			s.lineStarts = append(s.lineStarts, i+1)
+		}
+	}
+
+	for _, match := range matches {
+		s.spans = append(s.spans, match.spans(src)...)
+	}
+	sort.Slice(s.spans, func(i, j int) bool { return s.spans[i].start < s.spans[j].start })
+	s.maxEnd = make([]int, len(s.spans))
+	for i, sp := range s.spans {
+		s.maxEnd[i] = sp.end
+		if i > 0 {
+			s.maxEnd[i] = max(sp.end, s.maxEnd[i-1])
+		}
+	}
+	return s
+}
+
+func (s *snippets) snippet(match Match) string {
+	idx := match.Line - 1
+	if idx < 0 || idx >= len(s.lineStarts) {
+		return ""
+	}
+
+	first := max(idx-snippetContext, 0)
+	last := min(idx+snippetContext, len(s.lineStarts)-1)
+
+	snippet := make([]string, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		start, end := s.line(i)
+		if end-start > maxSnippetLine {
+			if i == idx {
+				// keep the window around the key rather than the start of the line
+				start += max(min(match.Column-1-maxSnippetLine/4, end-start-maxSnippetLine), 0)
+			}
+			end = start + maxSnippetLine
+		}
+		snippet = append(snippet, s.redact(start, end))
+	}
+	return strings.Join(snippet, "\n")
+}
+
+// position is Positioner.At for offsets in any order.
+func (s *snippets) position(offset int) (int, int) {
+	line := sort.Search(len(s.lineStarts), func(i int) bool { return s.lineStarts[i] > offset })
+	return line, offset - s.lineStarts[line-1] + 1
+}
+
+// line returns where line i (0-based) starts and ends, without its newline.
+func (s *snippets) line(i int) (int, int) {
+	start, end := s.lineStarts[i], len(s.src)
+	if i+1 < len(s.lineStarts) {
+		end = s.lineStarts[i+1] - 1
+	}
+	if end > start && s.src[end-1] == '\r' {
+		end--
+	}
+	return start, end
+}
+
+// redact returns src[start:end] with every key overlapping it masked the way
+// Redact masks it.
+func (s *snippets) redact(start, end int) string {
+	text := []byte(s.src[start:end])
+	i := sort.Search(len(s.spans), func(i int) bool { return s.spans[i].start >= end })
+	for i--; i >= 0 && s.maxEnd[i] > start; i-- {
+		sp := s.spans[i]
+		masked := sp.start + redactKeep(sp.end-sp.start)
+		for j := max(masked, start); j < min(sp.end, end); j++ {
+			text[j-start] = '*'
+		}
+	}
+	return string(text)
+}
+
+// spans finds the key in the source, or each part of a composite key.
+// Parts are searched for in order from the start of the match, then from
+// the start again for one that comes before the part listed ahead of it.
+func (m Match) spans(src string) []span {
+	if len(m.Parts) == 0 {
+		end := m.Offset + len(m.Key)
+		if m.Key == "" || end > len(src) || src[m.Offset:end] != m.Key {
+			return nil
+		}
+		return []span{{m.Offset, end}}
+	}
+
+	var spans []span
+	limit := min(m.end(), len(src))
+	from := m.Offset
+	for _, part := range m.Parts {
+		if part == "" || m.Offset > limit {
+			continue
+		}
+		i := strings.Index(src[from:limit], part)
+		start := from + i
+		if i < 0 {
+			if i = strings.Index(src[m.Offset:limit], part); i < 0 {
+				continue
+			}
+			start = m.Offset + i
+		}
+		spans = append(spans, span{start, start + len(part)})
+		from = start + len(part)
+	}
+	return spans
+}
@@ -0,0 +1,15 @@
+// Stripe live secret (sk_live_) and restricted (rk_live_) keys. Test mode
+// keys can't move money, so they're left alone.
+package detectors
+
+import (
+	"regexp"
+)
+
+var stripeKeyRegex = regexp.MustCompile(`\b[sr]k_live_[A-Za-z0-9]{24,247}\b`)
+
+var Stripe = NewRegexDetector("stripe", stripeKeyRegex)
+
+func init() {
+	Register(Stripe)
+}
@@ -0,0 +1,117 @@
+package detectors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Match.Suppressed values
+const (
+	SuppressedInline   = "inline"   // an AllowComment on the same line
+	SuppressedBaseline = "baseline" // recorded in a baseline file
+)
+
+// AllowComment marks a line whose secrets are known and accepted, e.g.
+// `key = "..." # openradar:allow`.
+const AllowComment = "openradar:allow"
+
+// allowedInline reports whether the line holding offset has an AllowComment.
+func allowedInline(src string, offset int) bool {
+	start := strings.LastIndexByte(src[:offset], '\n') + 1
+	end := strings.IndexByte(src[offset:], '\n')
+	if end < 0 {
+		end = len(src)
+	} else {
+		end += offset
+	}
+	return strings.Contains(src[start:end], AllowComment)
+}
+
+// Fingerprint identifies a secret in a file, wherever in the file it moves.
+// It's a hash, so baselines don't hold the secrets themselves.
+func Fingerprint(provider string, path string, key string) string {
+	sum := sha256.Sum256([]byte(provider + "\x00" + path + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+const baselineVersion = 1
+
+// Baseline is a set of findings that have been looked at and accepted, so
+// later scans only report new ones.
+type Baseline struct {
+	Version  int             `json:"version"`
+	Findings []BaselineEntry `json:"findings"`
+
+	index map[string]bool
+}
+
+// BaselineEntry records where a finding was, for whoever reviews the file;
+// only the fingerprint is matched on.
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Provider    string `json:"provider"`
+	Path        string `json:"path"`
+	Line        int    `json:"line"`
+}
+
+func NewBaseline() *Baseline {
+	return &Baseline{Version: baselineVersion, index: make(map[string]bool)}
+}
+
+// LoadBaseline reads a baseline file. A missing file is an os.ErrNotExist
+// error, so callers can tell it apart from a broken one.
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	baseline := NewBaseline()
+	if err := json.Unmarshal(data, baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	if baseline.Version != baselineVersion {
+		return nil, fmt.Errorf("baseline %s has unsupported version %d", path, baseline.Version)
+	}
+	for _, entry := range baseline.Findings {
+		baseline.index[entry.Fingerprint] = true
+	}
+	return baseline, nil
+}
+
+func (b *Baseline) Add(provider string, path string, key string, line int) {
+	fingerprint := Fingerprint(provider, path, key)
+	if b.index[fingerprint] {
+		return
+	}
+	b.index[fingerprint] = true
+	b.Findings = append(b.Findings, BaselineEntry{
+		Fingerprint: fingerprint,
+		Provider:    provider,
+		Path:        path,
+		Line:        line,
+	})
+}
+
+func (b *Baseline) Contains(provider string, path string, key string) bool {
+	return b.index[Fingerprint(provider, path, key)]
+}
+
+func (b *Baseline) Len() int {
+	return len(b.Findings)
+}
+
+func (b *Baseline) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+// Telegram bot tokens
+package detectors
+
+import (
+	"regexp"
+)
+
+// The bot's numeric ID, then a 35 character secret that always starts AA.
+var telegramKeyRegex = regexp.MustCompile(`\b[0-9]{8,10}:AA[A-Za-z0-9_-]{33}`)
+
+var Telegram = NewRegexDetector("telegram", telegramKeyRegex)
+
+func init() {
+	Register(Telegram)
+}
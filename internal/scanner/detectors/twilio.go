@@ -0,0 +1,23 @@
+// Twilio account SIDs and auth tokens, reported as SID:token pairs
+package detectors
+
+import (
+	"regexp"
+)
+
+var twilioSIDRegex = regexp.MustCompile(`\bAC[a-f0-9]{32}\b`)
+
+// Auth tokens are 32 hex characters, like any MD5, so one only counts with a
+// word like auth or token earlier on its line. Requiring non-alphanumerics
+// either side keeps the SID's own hex out.
+var twilioTokenRegex = regexp.MustCompile(`(?i:auth|token|secret|twilio)[^\n]{0,40}?[^A-Za-z0-9]([a-f0-9]{32})(?:[^A-Za-z0-9]|$)`)
+
+var Twilio Detector = &pairDetector{
+	provider: "twilio",
+	id:       twilioSIDRegex,
+	secret:   twilioTokenRegex,
+}
+
+func init() {
+	Register(Twilio)
+}
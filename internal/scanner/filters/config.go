@@ -0,0 +1,122 @@
+package filters
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+
+	"openradar/internal/scanner/detectors"
+)
+
+// DefaultPlaceholders are words that turn up in example keys but are
+// vanishingly unlikely in random ones.
+var DefaultPlaceholders = []string{
+	"your_api_key", "your-api-key", "yourapikey", "your_key", "your-key",
+	"api_key_here", "apikeyhere", "key_here", "placeholder", "example",
+	"changeme", "change_me", "replace_me", "replaceme", "insert_", "redacted",
+	"dummy", "notarealkey", "not_a_real", "xxxxx",
+}
+
+// Config tunes the filter pipeline. Loaded from a TOML file, on top of
+// DefaultConfig:
+//
+//	min_entropy = 3.0
+//	repeated_chars = 6
+//	sequential_chars = 7
+//	placeholders = ["sandbox"] # added to DefaultPlaceholders
+//
+//	[allowlists]
+//	groq = ['''^gsk_fixture''']
+//	"*" = ['''EXAMPLEKEY$''']
+type Config struct {
+	MinEntropy      float64             `toml:"min_entropy"`      // 0 = off
+	RepeatedChars   int                 `toml:"repeated_chars"`   // 0 = off
+	SequentialChars int                 `toml:"sequential_chars"` // 0 = off
+	Placeholders    []string            `toml:"placeholders"`
+	Allowlists      map[string][]string `toml:"allowlists"` // provider (or "*") to regexes
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MinEntropy:      3.0,
+		RepeatedChars:   6,
+		SequentialChars: 7,
+	}
+}
+
+// LoadFile builds a pipeline from a TOML config file.
+func LoadFile(path string) (*Pipeline, error) {
+	cfg := DefaultConfig()
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse filters file %s: %w", path, err)
+	}
+	return Build(cfg)
+}
+
+// Build turns a config into a pipeline. Cheap filters go first, so they
+// account for most rejections.
+func Build(cfg Config) (*Pipeline, error) {
+	allowlists := make(map[string][]*regexp.Regexp)
+	for provider, patterns := range cfg.Allowlists {
+		for _, pattern := range patterns {
+			regex, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid allowlist regex %q for %s: %w", pattern, provider, err)
+			}
+			allowlists[provider] = append(allowlists[provider], regex)
+		}
+	}
+
+	filters := []Filter{
+		Allowlist(allowlists),
+		Placeholders(append(append([]string{}, DefaultPlaceholders...), cfg.Placeholders...)),
+	}
+	if cfg.RepeatedChars > 0 {
+		filters = append(filters, RepeatedChars(cfg.RepeatedChars))
+	}
+	if cfg.SequentialChars > 0 {
+		filters = append(filters, SequentialChars(cfg.SequentialChars))
+	}
+	if cfg.MinEntropy > 0 {
+		filters = append(filters, MinEntropy(cfg.MinEntropy))
+	}
+	return New(filters...), nil
+}
+
+var (
+	defaultMu sync.RWMutex
+	pipeline  *Pipeline
+)
+
+func init() {
+	p, err := Build(DefaultConfig())
+	if err != nil {
+		panic(err)
+	}
+	pipeline = p
+}
+
+// Configure replaces the pipeline Check uses.
+func Configure(p *Pipeline) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	pipeline = p
+}
+
+// Check runs the configured pipeline.
+func Check(match detectors.Match) (Rejection, bool) {
+	defaultMu.RLock()
+	p := pipeline
+	defaultMu.RUnlock()
+	return p.Check(match)
+}
+
+// Counts returns the configured pipeline's rejections by reason.
+func Counts() map[string]int64 {
+	defaultMu.RLock()
+	p := pipeline
+	defaultMu.RUnlock()
+	return p.Counts()
+}
@@ -0,0 +1,180 @@
+// Drops detector matches that aren't real keys (placeholders, documentation
+// examples, low-entropy strings), recording why each one was dropped.
+package filters
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"openradar/internal/scanner/detectors"
+)
+
+// Reason codes, counted in metrics
+const (
+	ReasonLowEntropy  = "low_entropy"
+	ReasonPlaceholder = "placeholder"
+	ReasonRepeated    = "repeated_chars"
+	ReasonSequential  = "sequential_chars"
+	ReasonAllowlisted = "allowlisted"
+)
+
+// Rejection is why a match was dropped.
+type Rejection struct {
+	Reason string `json:"reason"`
+	Detail string `json:"detail"` // e.g. which placeholder matched
+}
+
+// Filter rejects matches that look fake. It reports false for matches it
+// has no opinion on.
+type Filter interface {
+	Check(match detectors.Match) (Rejection, bool)
+}
+
+// Pipeline runs filters in order, stopping at the first rejection, and
+// counts rejections by reason.
+type Pipeline struct {
+	filters []Filter
+
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func New(filters ...Filter) *Pipeline {
+	return &Pipeline{filters: filters, counts: make(map[string]int64)}
+}
+
+func (p *Pipeline) Check(match detectors.Match) (Rejection, bool) {
+	for _, filter := range p.filters {
+		if rejection, rejected := filter.Check(match); rejected {
+			p.mu.Lock()
+			p.counts[rejection.Reason]++
+			p.mu.Unlock()
+			return rejection, true
+		}
+	}
+	return Rejection{}, false
+}
+
+// Counts returns how many matches each reason has rejected.
+func (p *Pipeline) Counts() map[string]int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	counts := make(map[string]int64, len(p.counts))
+	for reason, count := range p.counts {
+		counts[reason] = count
+	}
+	return counts
+}
+
+type minEntropy float64
+
+// MinEntropy rejects keys with less than bits of Shannon entropy per
+// character, like sk-aaaaaaaabbbbbbbb.
+func MinEntropy(bits float64) Filter {
+	return minEntropy(bits)
+}
+
+func (f minEntropy) Check(match detectors.Match) (Rejection, bool) {
+	entropy := detectors.ShannonEntropy(match.Key)
+	if entropy >= float64(f) {
+		return Rejection{}, false
+	}
+	return Rejection{Reason: ReasonLowEntropy, Detail: fmt.Sprintf("%.2f < %.2f bits", entropy, float64(f))}, true
+}
+
+type placeholders []string
+
+// Placeholders rejects keys containing any of words, ignoring case.
+func Placeholders(words []string) Filter {
+	lower := make(placeholders, 0, len(words))
+	for _, word := range words {
+		lower = append(lower, strings.ToLower(word))
+	}
+	return lower
+}
+
+func (f placeholders) Check(match detectors.Match) (Rejection, bool) {
+	key := strings.ToLower(match.Key)
+	for _, word := range f {
+		if strings.Contains(key, word) {
+			return Rejection{Reason: ReasonPlaceholder, Detail: word}, true
+		}
+	}
+	return Rejection{}, false
+}
+
+type repeatedChars int
+
+// RepeatedChars rejects keys with n or more of the same character in a row,
+// like sk-xxxxxxxx.
+func RepeatedChars(n int) Filter {
+	return repeatedChars(n)
+}
+
+func (f repeatedChars) Check(match detectors.Match) (Rejection, bool) {
+	return checkRuns(match.Key, int(f), ReasonRepeated, func(prev, next byte) bool {
+		return next == prev
+	})
+}
+
+type sequentialChars int
+
+// SequentialChars rejects keys with n or more consecutive characters in a
+// row, counting up or down, like abcdefg or 987654.
+func SequentialChars(n int) Filter {
+	return sequentialChars(n)
+}
+
+func (f sequentialChars) Check(match detectors.Match) (Rejection, bool) {
+	if rejection, rejected := checkRuns(match.Key, int(f), ReasonSequential, func(prev, next byte) bool {
+		return next == prev+1
+	}); rejected {
+		return rejection, true
+	}
+	return checkRuns(match.Key, int(f), ReasonSequential, func(prev, next byte) bool {
+		return next == prev-1
+	})
+}
+
+// checkRuns rejects key if it has n or more characters in a row where each
+// follows on from the last.
+func checkRuns(key string, n int, reason string, follows func(prev, next byte) bool) (Rejection, bool) {
+	if n <= 1 {
+		return Rejection{}, false
+	}
+	start := 0
+	for i := 1; i <= len(key); i++ {
+		if i < len(key) && follows(key[i-1], key[i]) {
+			continue
+		}
+		if i-start >= n {
+			return Rejection{Reason: reason, Detail: key[start:i]}, true
+		}
+		start = i
+	}
+	return Rejection{}, false
+}
+
+// AnyProvider is the allowlist key that applies to every provider.
+const AnyProvider = "*"
+
+type allowlist map[string][]*regexp.Regexp
+
+// Allowlist rejects keys matching one of their provider's regexes, or one
+// of the AnyProvider regexes. Used for known fixtures and documentation keys.
+func Allowlist(regexes map[string][]*regexp.Regexp) Filter {
+	return allowlist(regexes)
+}
+
+func (f allowlist) Check(match detectors.Match) (Rejection, bool) {
+	for _, provider := range []string{match.Provider, AnyProvider} {
+		for _, regex := range f[provider] {
+			if regex.MatchString(match.Key) {
+				return Rejection{Reason: ReasonAllowlisted, Detail: provider + ": " + regex.String()}, true
+			}
+		}
+	}
+	return Rejection{}, false
+}
@@ -0,0 +1,234 @@
+// Walks git history and hands back the lines each commit added, so keys that
+// were committed and deleted again are still found.
+package history
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"openradar/internal/scanner/detectors"
+)
+
+type Options struct {
+	MaxCommits int                    // 0 = no limit
+	Since      time.Time              // zero = no limit
+	MaxSize    int                    // skip a file's additions in a commit past this many bytes
+	Include    func(path string) bool // nil = every file
+}
+
+// Commit is who made a commit and when.
+type Commit struct {
+	SHA         string
+	AuthorName  string
+	AuthorEmail string
+	Time        time.Time
+}
+
+// Chunk is every line one commit added to one file, joined so the detectors
+// can run over them in one go.
+type Chunk struct {
+	Commit Commit
+	Path   string
+	Text   string
+
+	lineNums []int // line number in the file of each line in Text
+}
+
+// commitFormat is parsed by parseCommit.
+const commitFormat = "%H%x00%an%x00%ae%x00%ct"
+
+func parseCommit(line string) Commit {
+	fields := strings.Split(line, "\x00")
+	commit := Commit{SHA: fields[0]}
+	if len(fields) == 4 {
+		commit.AuthorName = fields[1]
+		commit.AuthorEmail = fields[2]
+		if unix, err := strconv.ParseInt(fields[3], 10, 64); err == nil {
+			commit.Time = time.Unix(unix, 0).UTC()
+		}
+	}
+	return commit
+}
+
+// Head returns the commit checked out in dir.
+func Head(ctx context.Context, dir string) (Commit, error) {
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "log", "-1", "--format="+commitFormat).Output()
+	if err != nil {
+		return Commit{}, fmt.Errorf("git log failed: %w", err)
+	}
+	return parseCommit(strings.TrimSpace(string(out))), nil
+}
+
+// Walk runs git log over the clone in dir, oldest commit first, and calls
+// scan for every file each commit touched.
+func Walk(ctx context.Context, dir string, opts Options, scan func(chunk Chunk)) error {
+	args := []string{
+		"-C", dir, "-c", "core.quotePath=false",
+		"log", "--reverse", "--no-merges", "--no-color", "--no-ext-diff", "--no-renames",
+		"--unified=0", "--format=commit " + commitFormat, "-p",
+	}
+	if opts.MaxCommits > 0 {
+		args = append(args, "-n", strconv.Itoa(opts.MaxCommits))
+	}
+	if !opts.Since.IsZero() {
+		args = append(args, "--since", opts.Since.Format(time.RFC3339))
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to run git log: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run git log: %w", err)
+	}
+
+	if err := Parse(stdout, opts, scan); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return fmt.Errorf("failed to read git log: %w", err)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("git log failed: %w", err)
+	}
+	return nil
+}
+
+// Parse reads `git log -p --unified=0` output, with each commit's header
+// line being "commit " followed by commitFormat.
+func Parse(r io.Reader, opts Options, scan func(chunk Chunk)) error {
+	reader := bufio.NewReader(r)
+
+	var commit Commit
+	var chunk *Chunk
+	var text strings.Builder
+	skip := false
+	inHunk := false
+	nextLine := 0
+
+	flush := func() {
+		if chunk != nil && !skip && text.Len() > 0 {
+			chunk.Text = text.String()
+			scan(*chunk)
+		}
+		chunk = nil
+		text.Reset()
+		skip = false
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if line == "" && err == io.EOF {
+			break
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case strings.HasPrefix(line, "commit "):
+			flush()
+			commit = parseCommit(strings.TrimPrefix(line, "commit "))
+			inHunk = false
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			chunk = &Chunk{Commit: commit}
+			inHunk = false
+		case chunk == nil:
+			// between the commit line and the first diff
+		case strings.HasPrefix(line, "@@ "):
+			inHunk = true
+			nextLine = hunkStart(line)
+		case !inHunk:
+			if path, ok := strings.CutPrefix(line, "+++ "); ok {
+				if path == "/dev/null" { // deleted file
+					skip = true
+				} else {
+					chunk.Path = strings.TrimPrefix(strings.Trim(path, `"`), "b/")
+					skip = opts.Include != nil && !opts.Include(chunk.Path)
+				}
+			}
+		case strings.HasPrefix(line, "+"):
+			if !skip {
+				text.WriteString(line[1:])
+				text.WriteByte('\n')
+				chunk.lineNums = append(chunk.lineNums, nextLine)
+				if opts.MaxSize > 0 && text.Len() > opts.MaxSize {
+					log.Printf("skipping %s in %s because the diff is too large", chunk.Path, commit.SHA)
+					skip = true
+				}
+			}
+			nextLine++
+		case strings.HasPrefix(line, " "): // context line
+			nextLine++
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	flush()
+	return nil
+}
+
+// FromPatch collects the lines added by a single file's unified diff, as
+// found in GitHub's compare and commit APIs.
+func FromPatch(commit Commit, path string, patch string) Chunk {
+	chunk := Chunk{Commit: commit, Path: path}
+
+	var text strings.Builder
+	nextLine := 0
+	for _, line := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@ "):
+			nextLine = hunkStart(line)
+		case strings.HasPrefix(line, "+"):
+			text.WriteString(line[1:])
+			text.WriteByte('\n')
+			chunk.lineNums = append(chunk.lineNums, nextLine)
+			nextLine++
+		case strings.HasPrefix(line, " "):
+			nextLine++
+		}
+	}
+
+	chunk.Text = text.String()
+	return chunk
+}
+
+// hunkStart returns the new-file start line of a "@@ -a,b +c,d @@" header.
+func hunkStart(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return 1
+	}
+	start, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+	n, err := strconv.Atoi(start)
+	if err != nil {
+		return 1
+	}
+	return n
+}
+
+// Matches runs the detectors over the added lines and maps each match back
+// to its line in the file. Byte offsets into the file aren't known from a
+// diff, so they're left at zero.
+func (c Chunk) Matches() []detectors.Match {
+	matches := detectors.FindAll(c.Text)
+	for i := range matches {
+		matches[i].Line = c.lineNums[matches[i].Line-1]
+		matches[i].EndLine = c.lineNums[matches[i].EndLine-1]
+		matches[i].Offset = 0
+	}
+	return matches
+}
@@ -0,0 +1,162 @@
+// Scans local files, git history and stdin with the same detectors the
+// monitor uses, for pre-commit hooks and CI.
+package local
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"openradar/internal/scanner/detectors"
+	"openradar/internal/scanner/filters"
+	"openradar/internal/scanner/history"
+)
+
+// binarySniffLen is how much of a file is checked for NUL bytes before it's
+// skipped as binary, the same amount git looks at.
+const binarySniffLen = 8000
+
+type Options struct {
+	MaxFileSize int64 // skip files past this many bytes, 0 = no limit
+
+	// Findings in the baseline are reported as suppressed, nil = none
+	Baseline *detectors.Baseline
+
+	// Called for every match the filters drop, nil = ignore them
+	OnReject func(finding Finding, rejection filters.Rejection)
+
+	// History mode only
+	MaxCommits int       // 0 = no limit
+	Since      time.Time // zero = no limit
+}
+
+// Finding is a match along with where it was found.
+type Finding struct {
+	detectors.Match
+	Path   string
+	Commit history.Commit // history mode only
+}
+
+// ScanDir scans every text file under dir, skipping .git. Paths in findings
+// are relative to dir.
+func ScanDir(ctx context.Context, dir string, opts Options) ([]Finding, error) {
+	var findings []Finding
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if opts.MaxFileSize > 0 && info.Size() > opts.MaxFileSize {
+			return nil
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		relPath, _ := filepath.Rel(dir, path)
+		findings = append(findings, scan(src, filepath.ToSlash(relPath), history.Commit{}, opts)...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	return findings, nil
+}
+
+// ScanHistory scans the lines added by every commit in the git repository at
+// dir, oldest first, so keys that were committed and then deleted are found.
+func ScanHistory(ctx context.Context, dir string, opts Options) ([]Finding, error) {
+	var findings []Finding
+	walkOpts := history.Options{
+		MaxCommits: opts.MaxCommits,
+		Since:      opts.Since,
+		MaxSize:    int(opts.MaxFileSize),
+	}
+	err := history.Walk(ctx, dir, walkOpts, func(chunk history.Chunk) {
+		for _, match := range chunk.Matches() {
+			if finding, ok := newFinding(match, chunk.Path, chunk.Commit, opts); ok {
+				findings = append(findings, finding)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return findings, nil
+}
+
+// ScanReader scans everything read from r, reporting it under name.
+func ScanReader(r io.Reader, name string, opts Options) ([]Finding, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return scan(src, name, history.Commit{}, opts), nil
+}
+
+// Unsuppressed returns the findings nobody has accepted yet.
+func Unsuppressed(findings []Finding) []Finding {
+	var unsuppressed []Finding
+	for _, finding := range findings {
+		if finding.Suppressed == "" {
+			unsuppressed = append(unsuppressed, finding)
+		}
+	}
+	return unsuppressed
+}
+
+func scan(src []byte, path string, commit history.Commit, opts Options) []Finding {
+	if isBinary(src) {
+		return nil
+	}
+
+	var findings []Finding
+	for _, match := range detectors.FindAll(string(src)) {
+		if finding, ok := newFinding(match, path, commit, opts); ok {
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// newFinding reports false for matches the filters drop.
+func newFinding(match detectors.Match, path string, commit history.Commit, opts Options) (Finding, bool) {
+	finding := Finding{Match: match, Path: path, Commit: commit}
+	if rejection, rejected := filters.Check(match); rejected {
+		if opts.OnReject != nil {
+			opts.OnReject(finding, rejection)
+		}
+		return Finding{}, false
+	}
+
+	if match.Suppressed == "" && opts.Baseline != nil && opts.Baseline.Contains(match.Provider, path, match.Key) {
+		finding.Suppressed = detectors.SuppressedBaseline
+	}
+	return finding, true
+}
+
+func isBinary(src []byte) bool {
+	return bytes.IndexByte(src[:min(len(src), binarySniffLen)], 0) >= 0
+}
@@ -0,0 +1,146 @@
+// Formats local scan findings for people (text) and tools (JSON, SARIF).
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"openradar/internal/scanner/detectors"
+	"openradar/internal/scanner/local"
+)
+
+// Formats
+const (
+	Text  = "text"
+	JSON  = "json"
+	SARIF = "sarif"
+)
+
+var Formats = []string{Text, JSON, SARIF}
+
+type Options struct {
+	ShowSecrets bool // print keys in full instead of redacted
+}
+
+// Finding is how a local.Finding is written out as JSON.
+type Finding struct {
+	Provider    string     `json:"provider"`
+	Key         string     `json:"key"`
+	Path        string     `json:"path"`
+	Line        int        `json:"line"`
+	Column      int        `json:"column"`
+	Snippet     string     `json:"snippet"`
+	Confidence  string     `json:"confidence"`
+	CommitSHA   string     `json:"commit_sha,omitempty"`
+	AuthorName  string     `json:"author_name,omitempty"`
+	AuthorEmail string     `json:"author_email,omitempty"`
+	CommittedAt *time.Time `json:"committed_at,omitempty"`
+	Suppressed  string     `json:"suppressed,omitempty"` // inline or baseline
+}
+
+// Results is the JSON output. Suppressed findings are listed separately, so
+// tools that only look at findings don't trip on accepted ones.
+type Results struct {
+	Findings   []Finding `json:"findings"`
+	Suppressed []Finding `json:"suppressed"`
+}
+
+// Write writes findings to w in format.
+func Write(w io.Writer, format string, findings []local.Finding, opts Options) error {
+	switch format {
+	case Text:
+		return writeText(w, findings, opts)
+	case JSON:
+		return writeJSON(w, findings, opts)
+	case SARIF:
+		return writeSARIF(w, findings, opts)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func convert(finding local.Finding, opts Options) Finding {
+	converted := Finding{
+		Provider:    finding.Provider,
+		Key:         key(finding, opts),
+		Path:        finding.Path,
+		Line:        finding.Line,
+		Column:      finding.Column,
+		Snippet:     finding.Snippet,
+		Confidence:  finding.Confidence,
+		CommitSHA:   finding.Commit.SHA,
+		AuthorName:  finding.Commit.AuthorName,
+		AuthorEmail: finding.Commit.AuthorEmail,
+		Suppressed:  finding.Suppressed,
+	}
+	if !finding.Commit.Time.IsZero() {
+		committedAt := finding.Commit.Time
+		converted.CommittedAt = &committedAt
+	}
+	return converted
+}
+
+func key(finding local.Finding, opts Options) string {
+	if opts.ShowSecrets {
+		return finding.Key
+	}
+	return detectors.Redact(finding.Key)
+}
+
+func writeText(w io.Writer, findings []local.Finding, opts Options) error {
+	var suppressed int
+	for _, finding := range findings {
+		if finding.Suppressed != "" {
+			suppressed++
+			continue
+		}
+		if err := writeTextLine(w, finding, opts, ""); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "%d secret(s) found\n", len(findings)-suppressed); err != nil {
+		return err
+	}
+	if suppressed == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w, "\n%d suppressed:\n", suppressed); err != nil {
+		return err
+	}
+	for _, finding := range findings {
+		if finding.Suppressed != "" {
+			if err := writeTextLine(w, finding, opts, " ("+finding.Suppressed+")"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeTextLine(w io.Writer, finding local.Finding, opts Options, suffix string) error {
+	location := fmt.Sprintf("%s:%d:%d", finding.Path, finding.Line, finding.Column)
+	if finding.Commit.SHA != "" {
+		location = finding.Commit.SHA[:min(len(finding.Commit.SHA), 12)] + ":" + location
+	}
+	if finding.Confidence == detectors.ConfidenceLow {
+		suffix = " (low confidence)" + suffix
+	}
+	_, err := fmt.Fprintf(w, "%s: %s key %s%s\n", location, finding.Provider, key(finding, opts), suffix)
+	return err
+}
+
+func writeJSON(w io.Writer, findings []local.Finding, opts Options) error {
+	results := Results{Findings: []Finding{}, Suppressed: []Finding{}}
+	for _, finding := range findings {
+		if finding.Suppressed != "" {
+			results.Suppressed = append(results.Suppressed, convert(finding, opts))
+		} else {
+			results.Findings = append(results.Findings, convert(finding, opts))
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
@@ -0,0 +1,164 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"openradar/internal/scanner/detectors"
+	"openradar/internal/scanner/local"
+)
+
+// SARIF 2.1.0, just the parts code scanning tools read.
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "openradar"
+	toolURI      = "https://github.com/officialmelon/openradar"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string             `json:"ruleId"`
+	Level               string             `json:"level"`
+	Message             sarifMessage       `json:"message"`
+	Locations           []sarifLocation    `json:"locations"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints"`
+	Suppressions        []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+// sarifSuppression kinds are inSource for comments in the code, and
+// external for anything else, like a baseline file.
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// ruleID is the SARIF rule a provider's findings are reported under.
+func ruleID(provider string) string {
+	return provider + "-key"
+}
+
+func writeSARIF(w io.Writer, findings []local.Finding, opts Options) error {
+	providers := make(map[string]bool)
+	results := make([]sarifResult, 0, len(findings))
+	for _, finding := range findings {
+		providers[finding.Provider] = true
+
+		message := fmt.Sprintf("%s key %s", finding.Provider, key(finding, opts))
+		if finding.Commit.SHA != "" {
+			message += " added in commit " + finding.Commit.SHA
+		}
+
+		var suppressions []sarifSuppression
+		switch finding.Suppressed {
+		case detectors.SuppressedInline:
+			suppressions = []sarifSuppression{{Kind: "inSource", Justification: detectors.AllowComment}}
+		case detectors.SuppressedBaseline:
+			suppressions = []sarifSuppression{{Kind: "external", Justification: "in baseline"}}
+		}
+
+		// Generic secrets are often something else, so they're only warnings.
+		level := "error"
+		if finding.Confidence == detectors.ConfidenceLow {
+			level = "warning"
+		}
+
+		results = append(results, sarifResult{
+			RuleID:  ruleID(finding.Provider),
+			Level:   level,
+			Message: sarifMessage{Text: message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: finding.Path},
+					Region: sarifRegion{
+						StartLine:   max(finding.Line, 1),
+						StartColumn: max(finding.Column, 1),
+						EndLine:     max(finding.EndLine, finding.Line, 1),
+						EndColumn:   max(finding.EndColumn, 1),
+					},
+				},
+			}},
+			// The same key in the same file is the same alert, wherever it moves.
+			PartialFingerprints: map[string]string{
+				"openradar/v1": detectors.Fingerprint(finding.Provider, finding.Path, finding.Key),
+			},
+			Suppressions: suppressions,
+		})
+	}
+
+	rules := make([]sarifRule, 0, len(providers))
+	for provider := range providers {
+		rules = append(rules, sarifRule{
+			ID:               ruleID(provider),
+			ShortDescription: sarifMessage{Text: "Leaked " + provider + " key"},
+		})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           toolName,
+				InformationURI: toolURI,
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	})
+}
@@ -0,0 +1,100 @@
+package rules
+
+import (
+	"regexp"
+	"strings"
+
+	"openradar/internal/scanner/detectors"
+)
+
+// ruleDetector is a detectors.Detector compiled from a Rule.
+type ruleDetector struct {
+	id          string
+	provider    string
+	regex       *regexp.Regexp
+	secretGroup int
+	keywords    []string
+	entropy     float64
+	allowlists  []allowlist
+}
+
+func (d *ruleDetector) Provider() string {
+	return d.provider
+}
+
+func (d *ruleDetector) FindAll(src string) []detectors.Match {
+	if !d.hasKeyword(src) {
+		return nil
+	}
+
+	locs := d.regex.FindAllStringSubmatchIndex(src, -1)
+	if locs == nil { // no match
+		return nil
+	}
+
+	var matches []detectors.Match
+	pos := detectors.NewPositioner(src)
+	for _, loc := range locs {
+		start, end := d.secret(loc)
+		if start < 0 { // secret group didn't participate
+			continue
+		}
+
+		secret := src[start:end]
+		if d.entropy > 0 && detectors.ShannonEntropy(secret) < d.entropy {
+			continue
+		}
+		if d.allowed(secret) {
+			continue
+		}
+
+		line, column := pos.At(start)
+		matches = append(matches, detectors.Match{
+			Key:      secret,
+			Provider: d.provider,
+			Offset:   start,
+			Line:     line,
+			Column:   column,
+		})
+	}
+	return matches
+}
+
+// secret picks the key out of a match: the secret group if the rule has one,
+// otherwise the first group that matched anything, as gitleaks does, and the
+// whole match for a regex without groups.
+func (d *ruleDetector) secret(loc []int) (int, int) {
+	if d.secretGroup > 0 {
+		return loc[2*d.secretGroup], loc[2*d.secretGroup+1]
+	}
+	for group := 1; 2*group < len(loc); group++ {
+		if start, end := loc[2*group], loc[2*group+1]; start >= 0 && end > start {
+			return start, end
+		}
+	}
+	return loc[0], loc[1]
+}
+
+// hasKeyword is a cheap prefilter so rules only run their regex over files
+// that could plausibly contain a match.
+func (d *ruleDetector) hasKeyword(src string) bool {
+	if len(d.keywords) == 0 {
+		return true
+	}
+	lower := strings.ToLower(src)
+	for _, keyword := range d.keywords {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *ruleDetector) allowed(secret string) bool {
+	for _, a := range d.allowlists {
+		if a.allows(secret) {
+			return true
+		}
+	}
+	return false
+}
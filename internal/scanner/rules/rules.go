@@ -0,0 +1,210 @@
+// Declarative detector rules, loaded from a TOML file at startup.
+// The format is a superset of gitleaks' config, so gitleaks rule files load as-is.
+package rules
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"openradar/internal/scanner/detectors"
+)
+
+type File struct {
+	Title     string    `toml:"title"`
+	Allowlist Allowlist `toml:"allowlist"`
+	Rules     []Rule    `toml:"rules"`
+}
+
+type Rule struct {
+	ID          string      `toml:"id"`
+	Description string      `toml:"description"`
+	Provider    string      `toml:"provider"` // defaults to ID
+	Regex       string      `toml:"regex"`
+	SecretGroup int         `toml:"secretGroup"`
+	Keywords    []string    `toml:"keywords"`
+	Entropy     float64     `toml:"entropy"`
+	Allowlist   Allowlist   `toml:"allowlist"`  // gitleaks v8.0 - v8.20
+	Allowlists  []Allowlist `toml:"allowlists"` // gitleaks v8.21+
+	Examples    []string    `toml:"examples"`   // must match
+	Negatives   []string    `toml:"negatives"`  // must not match
+}
+
+type Allowlist struct {
+	Description string   `toml:"description"`
+	Regexes     []string `toml:"regexes"`
+	StopWords   []string `toml:"stopwords"`
+}
+
+// LoadFile parses and compiles every rule in path.
+func LoadFile(path string) ([]detectors.Detector, error) {
+	var file File
+	meta, err := toml.DecodeFile(path, &file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rules file %s: %w", path, err)
+	}
+	warnUnsupported(meta)
+	return Compile(file)
+}
+
+// Parse is LoadFile for rules already in memory.
+func Parse(data string) ([]detectors.Detector, error) {
+	var file File
+	meta, err := toml.Decode(data, &file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+	warnUnsupported(meta)
+	return Compile(file)
+}
+
+// warnUnsupported logs the gitleaks settings that were read but aren't
+// supported, like allowlist paths or a rule's path, so it's clear they're
+// not applied.
+func warnUnsupported(meta toml.MetaData) {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, key := range meta.Undecoded() {
+		if name := key.String(); !seen[name] {
+			seen[name] = true
+			keys = append(keys, name)
+		}
+	}
+	if len(keys) > 0 {
+		log.Printf("ignoring unsupported rules settings: %s", strings.Join(keys, ", "))
+	}
+}
+
+// Compile turns a parsed rules file into detectors, checking each rule
+// against its own examples.
+func Compile(file File) ([]detectors.Detector, error) {
+	global, err := compileAllowlist(file.Allowlist)
+	if err != nil {
+		return nil, fmt.Errorf("global allowlist: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var compiled []detectors.Detector
+	for _, rule := range file.Rules {
+		if rule.ID == "" {
+			return nil, fmt.Errorf("rule is missing an id")
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("duplicate rule id %s", rule.ID)
+		}
+		seen[rule.ID] = true
+		if rule.Regex == "" {
+			log.Printf("skipping rule %s: rules without a regex, like gitleaks' path-only rules, aren't supported", rule.ID)
+			continue
+		}
+
+		detector, err := compileRule(rule, global)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule.ID, err)
+		}
+		compiled = append(compiled, detector)
+	}
+	return compiled, nil
+}
+
+// LoadAndRegister loads path and adds every rule to detectors.AllDetectors.
+func LoadAndRegister(path string) (int, error) {
+	compiled, err := LoadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	for _, detector := range compiled {
+		detectors.Register(detector)
+	}
+	return len(compiled), nil
+}
+
+func compileRule(rule Rule, global allowlist) (*ruleDetector, error) {
+	regex, err := regexp.Compile(rule.Regex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+	if rule.SecretGroup < 0 || rule.SecretGroup > regex.NumSubexp() {
+		return nil, fmt.Errorf("secretGroup %d out of range", rule.SecretGroup)
+	}
+
+	allowlists := []allowlist{global}
+	for _, a := range append([]Allowlist{rule.Allowlist}, rule.Allowlists...) {
+		compiledAllowlist, err := compileAllowlist(a)
+		if err != nil {
+			return nil, err
+		}
+		allowlists = append(allowlists, compiledAllowlist)
+	}
+
+	keywords := make([]string, 0, len(rule.Keywords))
+	for _, keyword := range rule.Keywords {
+		keywords = append(keywords, strings.ToLower(keyword))
+	}
+
+	provider := rule.Provider
+	if provider == "" {
+		provider = rule.ID
+	}
+
+	detector := &ruleDetector{
+		id:          rule.ID,
+		provider:    provider,
+		regex:       regex,
+		secretGroup: rule.SecretGroup,
+		keywords:    keywords,
+		entropy:     rule.Entropy,
+		allowlists:  allowlists,
+	}
+
+	for _, example := range rule.Examples {
+		if len(detector.FindAll(example)) == 0 {
+			return nil, fmt.Errorf("example %q does not match", example)
+		}
+	}
+	for _, negative := range rule.Negatives {
+		if matches := detector.FindAll(negative); len(matches) != 0 {
+			return nil, fmt.Errorf("negative example %q matches %q", negative, matches[0].Key)
+		}
+	}
+
+	return detector, nil
+}
+
+type allowlist struct {
+	regexes   []*regexp.Regexp
+	stopWords []string
+}
+
+func compileAllowlist(a Allowlist) (allowlist, error) {
+	var compiled allowlist
+	for _, pattern := range a.Regexes {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return compiled, fmt.Errorf("invalid allowlist regex %q: %w", pattern, err)
+		}
+		compiled.regexes = append(compiled.regexes, regex)
+	}
+	for _, word := range a.StopWords {
+		compiled.stopWords = append(compiled.stopWords, strings.ToLower(word))
+	}
+	return compiled, nil
+}
+
+func (a allowlist) allows(secret string) bool {
+	for _, regex := range a.regexes {
+		if regex.MatchString(secret) {
+			return true
+		}
+	}
+	lower := strings.ToLower(secret)
+	for _, word := range a.stopWords {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
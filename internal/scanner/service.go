@@ -2,51 +2,22 @@ package scanner
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"net/http"
+	"openradar/internal/db"
 	"openradar/internal/domain"
 	"openradar/internal/queue"
+	"openradar/internal/sources"
+	"slices"
 	"sync"
 	"time"
-)
-
-var GITHUB_ENDPOINT = "https://api.github.com"
-
-var sharedClient = &http.Client{
-	Timeout: 10 * time.Second,
-	Transport: &http.Transport{
-		MaxIdleConns:        20,
-		MaxIdleConnsPerHost: 10,
-		IdleConnTimeout:     30 * time.Second,
-	},
-}
-
-type Event struct {
-	ID        string    `json:"id"`
-	Type      string    `json:"type"`
-	Repo      Repo      `json:"repo"`
-	CreatedAt time.Time `json:"created_at"`
-	Payload   Payload   `json:"payload"`
-}
-
-type Repo struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
-	URL  string `json:"url"`
-}
 
-type Payload struct {
-	Ref  string `json:"ref"`
-	Head string `json:"head"`
-}
+	"gorm.io/gorm"
+)
 
-type ScannedRepository struct {
-	Size      uint   `json:"size"`
-	Url       string `json:"url"`
-	Clone_Url string `json:"clone_url"`
-}
+// How often to poll a source before it's adapted to how busy the source is.
+const DefaultPollInterval = 35 * time.Second
 
 var (
 	recentlyScanned   = make(map[string]time.Time)
@@ -64,65 +35,116 @@ func cleanupRecentlyScanned() {
 	}
 }
 
-func ScanJob(ctx context.Context, GITHUB_TOKEN string) ([]Event, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", GITHUB_ENDPOINT+"/events", nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create req: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+GITHUB_TOKEN)
+// Each source's cursor is loaded from the database on its first poll.
+// Activity that couldn't be queued is kept until it can be: the source only
+// returns it again while its response changes, and after a 304 it's gone.
+var (
+	eventCursors   = make(map[string]*domain.EventCursor)
+	unqueued       = make(map[string][]sources.Activity)
+	eventCursorsMu sync.Mutex
+)
 
-	res, err := sharedClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("http call failed: %w", err)
+// ScanJob polls every source and queues a job for each repository or push
+// seen since the last activity processed. Sources that aren't due to be
+// polled yet are skipped, unless they have activity left from a poll that
+// couldn't all be queued. Without pushDiff, every job is a full scan, so
+// pushes are queued as plain repository jobs.
+func ScanJob(ctx context.Context, jobQueue queue.Queue, DBtoSaveIn *gorm.DB, pushDiff bool) ([]sources.Activity, error) {
+	eventCursorsMu.Lock()
+	defer eventCursorsMu.Unlock()
+
+	var processed []sources.Activity
+	var errs []error
+	for _, source := range sources.AllSources {
+		activity, err := pollSource(ctx, source, jobQueue, DBtoSaveIn, pushDiff)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name(), err))
+		}
+		processed = append(processed, activity...)
 	}
+	return processed, errors.Join(errs...)
+}
 
-	defer res.Body.Close()
-
-	var events []Event
-	if err := json.NewDecoder(res.Body).Decode(&events); err != nil {
-		log.Printf("json decode failed")
+func pollSource(ctx context.Context, source sources.Source, jobQueue queue.Queue, DBtoSaveIn *gorm.DB, pushDiff bool) ([]sources.Activity, error) {
+	cursor := eventCursors[source.Name()]
+	if cursor == nil {
+		var err error
+		if cursor, err = db.GetEventCursor(source.Name(), DBtoSaveIn); err != nil {
+			return nil, err
+		}
+		if cursor.PollIntervalSeconds == 0 {
+			cursor.PollIntervalSeconds = int(DefaultPollInterval / time.Second)
+		}
+		eventCursors[source.Name()] = cursor
 	}
 
-	cleanupRecentlyScanned()
+	left := unqueued[source.Name()]
+	fresh, err := source.Poll(ctx, cursor)
+	if errors.Is(err, sources.ErrNotDue) && len(left) == 0 {
+		return nil, nil
+	}
+	if err != nil && !errors.Is(err, sources.ErrNotDue) {
+		return nil, fmt.Errorf("failed to poll: %w", err)
+	}
 
-	recentlyScannedMu.Lock()
-	for _, x := range events {
-		if _, seen := recentlyScanned[x.Repo.URL]; seen {
-			continue
-		}
-		recentlyScanned[x.Repo.URL] = time.Now()
-		sampleJob := domain.NewScanJob(x.Repo.URL)
-		select {
-		case queue.JobQueue <- sampleJob:
-		default:
-			log.Printf("job queue full, dropping job for %s", x.Repo.URL)
+	// a poll that wasn't a 304 returns what's left again, followed by
+	// anything newer
+	activity := slices.Clone(left)
+	for _, a := range fresh {
+		if len(left) == 0 || a.ID > left[len(left)-1].ID {
+			activity = append(activity, a)
 		}
 	}
-	recentlyScannedMu.Unlock()
+	processed := enqueueActivity(ctx, activity, jobQueue, pushDiff)
+	if rest := activity[len(processed):]; len(rest) > 0 {
+		unqueued[source.Name()] = rest
+	} else {
+		delete(unqueued, source.Name())
+	}
+	if len(processed) > 0 {
+		cursor.LastEventID = processed[len(processed)-1].ID
+		cursor.Events += int64(len(processed))
+	}
+	cursor.UpdatedAt = time.Now()
+	if err := db.SaveEventCursor(cursor, DBtoSaveIn); err != nil {
+		return processed, err
+	}
 
-	return events, nil
+	return processed, nil
 }
 
-func ScanRepo(ctx context.Context, URL string, GITHUB_TOKEN string) (ScannedRepository, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", URL, nil)
-	if err != nil {
-		return ScannedRepository{}, fmt.Errorf("failed to create req: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+GITHUB_TOKEN)
+// enqueueActivity queues a job for each activity, oldest first, and returns
+// the activity it got through. It stops at the first one that can't be
+// queued so the cursor doesn't move past it.
+func enqueueActivity(ctx context.Context, activity []sources.Activity, jobQueue queue.Queue, pushDiff bool) []sources.Activity {
+	cleanupRecentlyScanned()
 
-	res, err := sharedClient.Do(req)
-	if err != nil {
-		return ScannedRepository{}, fmt.Errorf("http call failed: %w", err)
-	}
+	recentlyScannedMu.Lock()
+	defer recentlyScannedMu.Unlock()
 
-	defer res.Body.Close()
+	for i, x := range activity {
+		sampleJob := domain.NewScanJob(x.RepoURL)
+
+		// With push diffs on, pushes only need their own commits scanned, so
+		// they're deduped per push rather than per repo.
+		seenKey := x.RepoURL
+		if pushDiff && x.HeadSHA != "" {
+			sampleJob.BeforeSHA = x.BeforeSHA
+			sampleJob.HeadSHA = x.HeadSHA
+			sampleJob.Branch = x.Branch
+			seenKey += "@" + x.HeadSHA
+		}
 
-	var repo ScannedRepository
-	if err := json.NewDecoder(res.Body).Decode(&repo); err != nil {
-		log.Printf("json decode failed")
+		if _, seen := recentlyScanned[seenKey]; seen {
+			continue
+		}
+		recentlyScanned[seenKey] = time.Now()
+		if _, err := jobQueue.Enqueue(ctx, sampleJob); err != nil {
+			log.Printf("failed to enqueue job for %s: %v", x.RepoURL, err)
+			delete(recentlyScanned, seenKey) // try again on the next poll
+			return activity[:i]
+		}
 	}
 
-	return repo, nil
+	return activity
 }
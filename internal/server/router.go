@@ -1,12 +1,15 @@
 package server
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -21,6 +24,9 @@ import (
 	"openradar/app"
 
 	"openradar/internal/config"
+	"openradar/internal/domain"
+	"openradar/internal/github"
+	"openradar/internal/queue"
 
 	"github.com/gorilla/websocket"
 )
@@ -67,6 +73,21 @@ func (h *Hub) run() {
 	}
 }
 
+// BroadcastFinding sends a newly saved finding to every websocket client.
+// Repository messages are sent as-is; findings are tagged so clients can
+// tell them apart.
+func (h *Hub) BroadcastFinding(finding *domain.Finding) {
+	msg, err := json.Marshal(struct {
+		Type    string          `json:"type"`
+		Finding *domain.Finding `json:"finding"`
+	}{"finding", finding})
+	if err != nil {
+		log.Printf("failed to marshal finding: %v", err)
+		return
+	}
+	h.Broadcast <- msg
+}
+
 func (h *Hub) add(conn *websocket.Conn) {
 	h.mu.Lock()
 	h.clients[conn] = true
@@ -131,13 +152,32 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// adminMiddleware only lets through requests carrying the admin token. With
+// no token configured, every admin endpoint is off.
+func adminMiddleware(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" {
+				http.NotFound(w, r)
+				return
+			}
+			given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(v)
 }
 
-func StartServer(db *gorm.DB, cfg config.Config) *Hub {
+func StartServer(db *gorm.DB, cfg config.Config, jobQueue queue.Queue, githubClient *github.Client) *Hub {
 	router := chi.NewRouter()
 
 	ipl := newIPLimiter()
@@ -207,6 +247,10 @@ func StartServer(db *gorm.DB, cfg config.Config) *Hub {
 			minAge,
 			db,
 		)
+		if errors.Is(err, api.ErrInvalidQuery) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		if err != nil {
 			log.Printf("GET /findings error: %v", err)
 			http.Error(w, "failed to fetch findings", http.StatusInternalServerError)
@@ -348,6 +392,117 @@ func StartServer(db *gorm.DB, cfg config.Config) *Hub {
 		writeJSON(w, http.StatusOK, findings)
 	})
 
+	// This lists scan jobs, newest first, optionally filtered by status
+	router.Get("/api/jobs", func(w http.ResponseWriter, r *http.Request) {
+		pageStr := r.URL.Query().Get("page")
+		page := 1
+		if pageStr != "" {
+			if val, err := strconv.Atoi(pageStr); err == nil && val > 0 {
+				page = val
+			}
+		}
+
+		pageSizeStr := r.URL.Query().Get("page_size")
+		pageSize := 25
+		if pageSizeStr != "" {
+			if val, err := strconv.Atoi(pageSizeStr); err == nil && val > 0 && val <= 100 {
+				pageSize = val
+			}
+		}
+
+		status := r.URL.Query().Get("status")
+		if status == "" {
+			status = "*"
+		}
+
+		paginatedJobs, err := api.GetScanJobs(page, pageSize, status, db)
+		if err != nil {
+			log.Printf("GET /jobs error: %v", err)
+			http.Error(w, "failed to fetch jobs", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, paginatedJobs)
+	})
+
+	// This returns a single scan job along with its status history
+	router.Get("/api/jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		job, err := api.GetScanJob(chi.URLParam(r, "id"), db)
+		if err != nil {
+			log.Printf("GET /jobs/{id} error: %v", err)
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, job)
+	})
+
+	// Admin only: these move dead jobs back onto the queue and show our tokens
+	router.Group(func(admin chi.Router) {
+		admin.Use(adminMiddleware(cfg.Admin.Token))
+
+		admin.Post("/api/admin/jobs/{id}/requeue", func(w http.ResponseWriter, r *http.Request) {
+			job, err := api.RequeueScanJob(r.Context(), chi.URLParam(r, "id"), jobQueue, db)
+			switch {
+			case errors.Is(err, queue.ErrJobNotFound):
+				http.Error(w, "Job not found", http.StatusNotFound)
+				return
+			case errors.Is(err, queue.ErrJobNotDead), errors.Is(err, queue.ErrJobActive):
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			case err != nil:
+				log.Printf("POST /admin/jobs/{id}/requeue error: %v", err)
+				http.Error(w, "failed to requeue job", http.StatusInternalServerError)
+				return
+			}
+
+			writeJSON(w, http.StatusOK, job)
+		})
+
+		admin.Post("/api/admin/jobs/requeue", func(w http.ResponseWriter, r *http.Request) {
+			requeued, err := api.RequeueDeadScanJobs(r.Context(), jobQueue, db)
+			if err != nil {
+				log.Printf("POST /admin/jobs/requeue error: %v", err)
+				http.Error(w, "failed to requeue jobs", http.StatusInternalServerError)
+				return
+			}
+
+			writeJSON(w, http.StatusOK, map[string]int{"requeued": requeued})
+		})
+
+		// This returns the quota and request count of each token
+		admin.Get("/api/github/tokens", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, githubClient.Tokens())
+		})
+	})
+
+	// This returns the GitHub API quota left across all our tokens
+	router.Get("/api/github/rate-limit", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, githubClient.RateLimit())
+	})
+
+	// This returns how far through each source's activity we are, and how often it outran us
+	router.Get("/api/metrics/events", func(w http.ResponseWriter, r *http.Request) {
+		cursors, err := api.GetEventCursors(db)
+		if err != nil {
+			log.Printf("GET /metrics/events error: %v", err)
+			http.Error(w, "failed to fetch event cursors", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, cursors)
+	})
+
+	// This returns how long verified keys stayed live before being revoked, per provider and per owner
+	router.Get("/api/metrics/revocation", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, api.GetRevocationMetrics())
+	})
+
+	// This returns how many matches the false-positive filters have dropped since startup, by reason
+	router.Get("/api/metrics/filters", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, api.GetFilterMetrics())
+	})
+
 	fileServer := http.FileServer(http.FS(distFS))
 
 	// This returns the page for the leaderboard (/* requires .html in the name, hence why we do this)
@@ -0,0 +1,128 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"openradar/internal/domain"
+	"openradar/internal/github"
+)
+
+const (
+	giteaPerPage  = 50 // the most Gitea returns by default
+	giteaMaxPages = 3
+)
+
+type giteaRepository struct {
+	ID            int64     `json:"id"`
+	FullName      string    `json:"full_name"`
+	HTMLURL       string    `json:"html_url"`
+	CloneURL      string    `json:"clone_url"`
+	DefaultBranch string    `json:"default_branch"`
+	Size          uint      `json:"size"`
+	Private       bool      `json:"private"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type giteaSearchResults struct {
+	Data []giteaRepository `json:"data"`
+}
+
+// Gitea (and Forgejo) has no public activity feed either, so it watches
+// repository search ordered by last update.
+type Gitea struct {
+	client  *github.Client
+	token   string
+	baseURL string
+	host    string
+}
+
+// NewGitea watches the Gitea at baseURL, e.g. https://gitea.com. The token
+// is optional.
+func NewGitea(baseURL string, token string) *Gitea {
+	pool := github.NewPool()
+	if token != "" {
+		pool.Add(github.StaticToken(token))
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &Gitea{
+		client:  github.NewClient(baseURL+"/api/v1", pool),
+		token:   token,
+		baseURL: baseURL,
+		host:    hostOf(baseURL),
+	}
+}
+
+func (s *Gitea) Name() string {
+	return "gitea"
+}
+
+// Poll treats each time a repository was updated as one activity,
+// identified by timedActivityID. Gitea only gives the time to the second, so
+// many repositories share one.
+func (s *Gitea) Poll(ctx context.Context, cursor *domain.EventCursor) ([]Activity, error) {
+	url := fmt.Sprintf("%s/repos/search?sort=updated&order=desc&limit=%d", s.client.BaseURL, giteaPerPage)
+	fetched, err := fetchPages(ctx, s.client, url, cursor.PollInterval(), giteaMaxPages, cursor.LastEventID, s.activity)
+	if err != nil {
+		return nil, err
+	}
+	return advance(s.Name(), cursor, fetched, giteaPerPage*giteaMaxPages), nil
+}
+
+func (s *Gitea) activity(results giteaSearchResults) []Activity {
+	activity := make([]Activity, 0, len(results.Data))
+	for _, repo := range results.Data {
+		if repo.Private { // visible to our token, but not public
+			continue
+		}
+		activity = append(activity, Activity{
+			ID:      timedActivityID(repo.UpdatedAt, repo.ID),
+			RepoURL: s.repoURL(repo.FullName),
+		})
+	}
+	return activity
+}
+
+func (s *Gitea) repoURL(fullName string) string {
+	return s.client.BaseURL + "/repos/" + fullName
+}
+
+func (s *Gitea) Repository(ctx context.Context, repoURL string) (Repository, error) {
+	var repo giteaRepository
+	if err := getRepository(ctx, s.client, repoURL, &repo); err != nil {
+		return Repository{}, err
+	}
+	return Repository{
+		URL:           s.repoURL(repo.FullName),
+		Name:          repo.FullName,
+		WebURL:        repo.HTMLURL,
+		CloneURL:      repo.CloneURL,
+		DefaultBranch: repo.DefaultBranch,
+		Size:          repo.Size,
+	}, nil
+}
+
+func (s *Gitea) CloneURL(ctx context.Context, repo Repository) (string, error) {
+	return withToken(repo.CloneURL, "oauth2", s.token)
+}
+
+// Identity parses repository URLs like https://gitea.com/api/v1/repos/owner/name.
+func (s *Gitea) Identity(repoURL string) (Identity, bool) {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Host != s.host {
+		return Identity{}, false
+	}
+	path, ok := strings.CutPrefix(u.Path, "/api/v1/repos/")
+	if !ok {
+		return Identity{}, false
+	}
+
+	owner, name, ok := strings.Cut(path, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return Identity{}, false
+	}
+	return Identity{Host: s.host, Owner: owner, Name: name, WebURL: s.baseURL + "/" + path}, true
+}
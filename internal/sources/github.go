@@ -0,0 +1,172 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"openradar/internal/domain"
+	"openradar/internal/github"
+)
+
+// GitHub only keeps the last 300 public events, 100 to a page.
+const (
+	githubEventsPerPage = 100
+	githubMaxEvents     = 300
+)
+
+// GitHub stops listing files in a comparison past this many.
+const MaxComparisonFiles = 300
+
+// A push that created a branch has no before commit.
+const nullSHA = "0000000000000000000000000000000000000000"
+
+type githubEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Repo struct {
+		URL string `json:"url"`
+	} `json:"repo"`
+	Payload struct {
+		Ref    string `json:"ref"`
+		Before string `json:"before"`
+		Head   string `json:"head"`
+	} `json:"payload"`
+}
+
+type githubRepository struct {
+	URL           string `json:"url"`
+	FullName      string `json:"full_name"`
+	HTMLURL       string `json:"html_url"`
+	CloneURL      string `json:"clone_url"`
+	DefaultBranch string `json:"default_branch"`
+	Size          uint   `json:"size"`
+}
+
+// GitHub watches the public events feed of github.com or a GitHub Enterprise
+// Server.
+type GitHub struct {
+	client    *github.Client
+	webURL    string
+	webHost   string
+	cloneHost string
+}
+
+// NewGitHub watches the GitHub that client talks to. webURL is where its
+// repositories are browsed, e.g. https://github.com. Clones go to cloneHost
+// instead of the host the API hands out, if it's set.
+func NewGitHub(client *github.Client, webURL string, cloneHost string) *GitHub {
+	webURL = strings.TrimRight(webURL, "/")
+	return &GitHub{
+		client:    client,
+		webURL:    webURL,
+		webHost:   hostOf(webURL),
+		cloneHost: cloneHost,
+	}
+}
+
+func (s *GitHub) Name() string {
+	return "github"
+}
+
+// Poll reads every page of /events back to the last event processed.
+func (s *GitHub) Poll(ctx context.Context, cursor *domain.EventCursor) ([]Activity, error) {
+	url := fmt.Sprintf("%s/events?per_page=%d", s.client.BaseURL, githubEventsPerPage)
+	fetched, err := fetchPages(ctx, s.client, url, cursor.PollInterval(), githubMaxEvents/githubEventsPerPage, cursor.LastEventID, githubActivity)
+	if err != nil {
+		return nil, err
+	}
+	return advance(s.Name(), cursor, fetched, githubMaxEvents), nil
+}
+
+func githubActivity(events []githubEvent) []Activity {
+	activity := make([]Activity, 0, len(events))
+	for _, event := range events {
+		id, _ := strconv.ParseInt(event.ID, 10, 64)
+		a := Activity{ID: id, RepoURL: event.Repo.URL}
+
+		// Pushes only need their own commits scanned.
+		payload := event.Payload
+		if event.Type == "PushEvent" && payload.Head != "" && payload.Before != "" && payload.Before != nullSHA {
+			a.BeforeSHA = payload.Before
+			a.HeadSHA = payload.Head
+			a.Branch = strings.TrimPrefix(payload.Ref, "refs/heads/")
+		}
+		activity = append(activity, a)
+	}
+	return activity
+}
+
+func (s *GitHub) Repository(ctx context.Context, repoURL string) (Repository, error) {
+	var repo githubRepository
+	if err := getRepository(ctx, s.client, repoURL, &repo); err != nil {
+		return Repository{}, err
+	}
+	return Repository{
+		URL:           repo.URL,
+		Name:          repo.FullName,
+		WebURL:        repo.HTMLURL,
+		CloneURL:      repo.CloneURL,
+		DefaultBranch: repo.DefaultBranch,
+		Size:          repo.Size,
+	}, nil
+}
+
+func (s *GitHub) CloneURL(ctx context.Context, repo Repository) (string, error) {
+	cloneURL := repo.CloneURL
+	if s.cloneHost != "" {
+		u, err := url.Parse(cloneURL)
+		if err != nil {
+			return "", fmt.Errorf("invalid clone url %s: %w", cloneURL, err)
+		}
+		u.Host = s.cloneHost
+		cloneURL = u.String()
+	}
+	return s.client.CloneURL(ctx, cloneURL)
+}
+
+// Identity understands both API URLs (https://api.github.com/repos/owner/name,
+// or https://ghes.example.com/api/v3/repos/owner/name) and web URLs
+// (https://github.com/owner/name).
+func (s *GitHub) Identity(repoURL string) (Identity, bool) {
+	path, ok := strings.CutPrefix(repoURL, s.client.BaseURL+"/repos/")
+	if !ok {
+		if path, ok = strings.CutPrefix(repoURL, s.webURL+"/"); !ok {
+			return Identity{}, false
+		}
+	}
+
+	owner, name, ok := strings.Cut(path, "/")
+	if !ok || owner == "" || name == "" {
+		return Identity{}, false
+	}
+	name, _, _ = strings.Cut(name, "/")
+	name = strings.TrimSuffix(name, ".git")
+	return Identity{
+		Host:   s.webHost,
+		Owner:  owner,
+		Name:   name,
+		WebURL: s.webURL + "/" + owner + "/" + name,
+		Avatar: s.webURL + "/" + owner + ".png",
+	}, true
+}
+
+// Compare fetches the changes between two commits, with a patch for each
+// changed file.
+func (s *GitHub) Compare(ctx context.Context, repoURL string, before string, head string) (Comparison, error) {
+	var comparison Comparison
+	if _, err := s.client.Get(ctx, repoURL+"/compare/"+before+"..."+head, &comparison); err != nil {
+		return Comparison{}, fmt.Errorf("compare failed: %w", err)
+	}
+	return comparison, nil
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,146 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"openradar/internal/domain"
+	"openradar/internal/github"
+)
+
+const (
+	gitlabPerPage  = 100
+	gitlabMaxPages = 3
+)
+
+type gitlabProject struct {
+	ID                int64     `json:"id"`
+	PathWithNamespace string    `json:"path_with_namespace"`
+	WebURL            string    `json:"web_url"`
+	HTTPURLToRepo     string    `json:"http_url_to_repo"`
+	DefaultBranch     string    `json:"default_branch"`
+	LastActivityAt    time.Time `json:"last_activity_at"`
+	Statistics        struct {
+		RepositorySize uint `json:"repository_size"` // bytes
+	} `json:"statistics"`
+}
+
+// GitLab has no public activity feed, so it watches the list of public
+// projects ordered by when they were last active. Its API is close enough
+// to GitHub's (Link pagination, ETags, bearer tokens) to share the client.
+type GitLab struct {
+	client  *github.Client
+	token   string
+	baseURL string
+	host    string
+}
+
+// NewGitLab watches the GitLab at baseURL, e.g. https://gitlab.com. The token
+// is optional.
+func NewGitLab(baseURL string, token string) *GitLab {
+	pool := github.NewPool()
+	if token != "" {
+		pool.Add(github.StaticToken(token))
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &GitLab{
+		client:  github.NewClient(baseURL+"/api/v4", pool),
+		token:   token,
+		baseURL: baseURL,
+		host:    hostOf(baseURL),
+	}
+}
+
+func (s *GitLab) Name() string {
+	return "gitlab"
+}
+
+// Poll treats each time a project was active as one activity, identified by
+// timedActivityID since GitLab has no public event IDs.
+func (s *GitLab) Poll(ctx context.Context, cursor *domain.EventCursor) ([]Activity, error) {
+	url := fmt.Sprintf("%s/projects?visibility=public&order_by=last_activity_at&sort=desc&simple=true&per_page=%d", s.client.BaseURL, gitlabPerPage)
+	fetched, err := fetchPages(ctx, s.client, url, cursor.PollInterval(), gitlabMaxPages, cursor.LastEventID, s.activity)
+	if err != nil {
+		return nil, err
+	}
+	return advance(s.Name(), cursor, fetched, gitlabPerPage*gitlabMaxPages), nil
+}
+
+func (s *GitLab) activity(projects []gitlabProject) []Activity {
+	activity := make([]Activity, 0, len(projects))
+	for _, project := range projects {
+		activity = append(activity, Activity{
+			ID:      timedActivityID(project.LastActivityAt, project.ID),
+			RepoURL: s.projectURL(project.PathWithNamespace),
+		})
+	}
+	return activity
+}
+
+// projectURL uses the project's path rather than its numeric ID, so the
+// owner can be read straight from it.
+func (s *GitLab) projectURL(path string) string {
+	return s.client.BaseURL + "/projects/" + url.PathEscape(path)
+}
+
+// Repository asks for the project's statistics to get its size. GitLab only
+// returns them to tokens with at least reporter access, so the size is often
+// 0.
+func (s *GitLab) Repository(ctx context.Context, repoURL string) (Repository, error) {
+	var project gitlabProject
+	if err := getRepository(ctx, s.client, repoURL+"?statistics=true", &project); err != nil {
+		return Repository{}, err
+	}
+	return Repository{
+		URL:           s.projectURL(project.PathWithNamespace),
+		Name:          project.PathWithNamespace,
+		WebURL:        project.WebURL,
+		CloneURL:      project.HTTPURLToRepo,
+		DefaultBranch: project.DefaultBranch,
+		Size:          project.Statistics.RepositorySize / 1024,
+	}, nil
+}
+
+func (s *GitLab) CloneURL(ctx context.Context, repo Repository) (string, error) {
+	return withToken(repo.CloneURL, "oauth2", s.token)
+}
+
+// Identity parses project URLs like
+// https://gitlab.com/api/v4/projects/group%2Fsubgroup%2Fname, where the owner
+// is everything before the last slash.
+func (s *GitLab) Identity(repoURL string) (Identity, bool) {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Host != s.host {
+		return Identity{}, false
+	}
+	escaped, ok := strings.CutPrefix(u.EscapedPath(), "/api/v4/projects/")
+	if !ok {
+		return Identity{}, false
+	}
+	path, err := url.PathUnescape(escaped)
+	if err != nil {
+		return Identity{}, false
+	}
+
+	i := strings.LastIndex(path, "/")
+	if i <= 0 || i == len(path)-1 {
+		return Identity{}, false
+	}
+	return Identity{Host: s.host, Owner: path[:i], Name: path[i+1:], WebURL: s.baseURL + "/" + path}, true
+}
+
+// withToken adds a token to a clone URL, if there is one.
+func withToken(cloneURL string, username string, token string) (string, error) {
+	if token == "" {
+		return cloneURL, nil
+	}
+	u, err := url.Parse(cloneURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid clone url: %w", err)
+	}
+	u.User = url.UserPassword(username, token)
+	return u.String(), nil
+}
@@ -0,0 +1,113 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"openradar/internal/domain"
+	"openradar/internal/github"
+)
+
+// The poll interval moves between these depending on how busy a source is.
+// GitHub's X-Poll-Interval still has the final say.
+const (
+	MinPollInterval = 10 * time.Second
+	MaxPollInterval = 2 * time.Minute
+)
+
+const repoIDDigits = 1000000 // room for a repository ID in an activity ID
+
+// timedActivityID identifies activity on a repository by when it happened,
+// for sources without public event IDs: the time in milliseconds followed by
+// the last six digits of the repository's ID. Repositories active at the
+// same time still get different IDs, and the cursor can stop between them.
+func timedActivityID(at time.Time, repoID int64) int64 {
+	return at.UnixMilli()*repoIDDigits + repoID%repoIDDigits
+}
+
+// fetchPages polls the first page of url and follows the Link header for up
+// to maxPages, stopping once it reaches lastID. Nothing is returned if the
+// first page hasn't changed since the last poll.
+func fetchPages[P any](ctx context.Context, client *github.Client, url string, interval time.Duration, maxPages int, lastID int64, activity func(P) []Activity) ([]Activity, error) {
+	var first P
+	res, err := client.Poll(ctx, url, interval, &first)
+	if err != nil {
+		return nil, err
+	}
+	if res.NotModified {
+		return nil, nil
+	}
+
+	fetched := activity(first)
+	for pages, next := 1, res.NextURL; next != "" && pages < maxPages && !reaches(fetched, lastID); pages++ {
+		var page P
+		res, err := client.Get(ctx, next, &page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", next, err)
+		}
+		fetched = append(fetched, activity(page)...)
+		next = res.NextURL
+	}
+	return fetched, nil
+}
+
+// advance picks the new activity out of a poll and updates the cursor's gap
+// count and poll interval to match. capacity is the most a poll can return.
+func advance(source string, cursor *domain.EventCursor, fetched []Activity, capacity int) []Activity {
+	fresh, gap := NewActivity(fetched, cursor.LastEventID)
+	if gap {
+		cursor.Gaps++
+		log.Printf("missed %s activity: it moved past %d between polls (%d gaps so far)", source, cursor.LastEventID, cursor.Gaps)
+	}
+	cursor.PollIntervalSeconds = int(NextPollInterval(cursor.PollInterval(), len(fresh), capacity, gap) / time.Second)
+	return fresh
+}
+
+// reaches reports whether activity goes back as far as lastID.
+func reaches(activity []Activity, lastID int64) bool {
+	if lastID == 0 {
+		return false
+	}
+	for _, a := range activity {
+		if a.ID <= lastID {
+			return true
+		}
+	}
+	return false
+}
+
+// NewActivity picks the activity after lastID, oldest first. If none of it
+// goes back as far as lastID, more happened between polls than the source
+// lets us see, and some was missed: that's a gap.
+func NewActivity(activity []Activity, lastID int64) ([]Activity, bool) {
+	var fresh []Activity
+	for _, a := range activity {
+		if a.ID > lastID {
+			fresh = append(fresh, a)
+		}
+	}
+	sort.SliceStable(fresh, func(i, j int) bool {
+		return fresh[i].ID < fresh[j].ID
+	})
+
+	gap := lastID != 0 && len(activity) > 0 && !reaches(activity, lastID)
+	return fresh, gap
+}
+
+// NextPollInterval speeds polling up when most of what a poll can return was
+// new (or we fell behind entirely), and slows it down when hardly any was.
+func NextPollInterval(current time.Duration, fresh int, capacity int, gap bool) time.Duration {
+	next := current
+	switch {
+	case gap:
+		next = MinPollInterval
+	case fresh >= capacity/2:
+		next = current / 2
+	case fresh < capacity/10:
+		next = current * 3 / 2
+	}
+	return min(max(next, MinPollInterval), MaxPollInterval)
+}
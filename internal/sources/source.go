@@ -0,0 +1,157 @@
+// Sources are the code hosts we watch for new activity. Each one knows how
+// to poll for recently changed repositories, look a repository up, and
+// clone it.
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"openradar/internal/domain"
+	"openradar/internal/github"
+)
+
+// ErrRepoUnavailable means the repository is gone (deleted, made private or
+// taken down), so there's no point trying again.
+var ErrRepoUnavailable = errors.New("repository unavailable")
+
+// ErrNotDue means a poll was skipped because it's too soon.
+var ErrNotDue = github.ErrNotDue
+
+// Activity is something that happened to a repository, like a push.
+type Activity struct {
+	ID        int64  // increases over time; cursors remember the last one processed
+	RepoURL   string // the API URL of the repository
+	BeforeSHA string // pushes only
+	HeadSHA   string
+	Branch    string
+}
+
+// Repository is what a source knows about a repository.
+type Repository struct {
+	URL           string `json:"url"`  // the API URL, which jobs and findings refer to
+	Name          string `json:"name"` // owner/name
+	WebURL        string `json:"web_url"`
+	CloneURL      string `json:"clone_url"`
+	DefaultBranch string `json:"default_branch"`
+	Size          uint   `json:"size"` // in KB, 0 if the source doesn't say
+}
+
+// Identity is the canonical name of a repository, whatever URL it was
+// referred to by.
+type Identity struct {
+	Host   string `json:"host"`
+	Owner  string `json:"owner"`
+	Name   string `json:"name"`
+	WebURL string `json:"web_url"` // the repository's page
+	Avatar string `json:"avatar"`  // the owner's avatar, if the source has one
+}
+
+func (i Identity) String() string {
+	return i.Host + "/" + i.Owner + "/" + i.Name
+}
+
+type Source interface {
+	Name() string
+	// Poll returns the activity since cursor.LastEventID, oldest first. It
+	// updates the cursor's gap count and poll interval, but moving
+	// LastEventID on is up to the caller. ErrNotDue means it's too soon.
+	Poll(ctx context.Context, cursor *domain.EventCursor) ([]Activity, error)
+	Repository(ctx context.Context, repoURL string) (Repository, error)
+	// CloneURL is repo.CloneURL, with credentials if the source has any.
+	CloneURL(ctx context.Context, repo Repository) (string, error)
+	// Identity parses one of this source's repository URLs, and reports
+	// false for URLs that belong to another source.
+	Identity(repoURL string) (Identity, bool)
+}
+
+// Comparer is a Source that can diff two commits, so pushes can be scanned
+// without cloning.
+type Comparer interface {
+	Compare(ctx context.Context, repoURL string, before string, head string) (Comparison, error)
+}
+
+// ChangedFile is one file in a comparison.
+type ChangedFile struct {
+	Filename string `json:"filename"`
+	Status   string `json:"status"`
+	Patch    string `json:"patch"`
+}
+
+type CompareCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Author struct {
+			Name  string    `json:"name"`
+			Email string    `json:"email"`
+			Date  time.Time `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+type Comparison struct {
+	Commits []CompareCommit `json:"commits"`
+	Files   []ChangedFile   `json:"files"`
+}
+
+var AllSources []Source
+
+func Register(source Source) {
+	AllSources = append(AllSources, source)
+}
+
+// For finds the source a repository URL belongs to.
+func For(repoURL string) (Source, bool) {
+	for _, source := range AllSources {
+		if _, ok := source.Identity(repoURL); ok {
+			return source, true
+		}
+	}
+	return nil, false
+}
+
+// IdentityOf parses a repository URL from any registered source.
+func IdentityOf(repoURL string) (Identity, bool) {
+	for _, source := range AllSources {
+		if identity, ok := source.Identity(repoURL); ok {
+			return identity, true
+		}
+	}
+	return Identity{}, false
+}
+
+// SetDisplayURL fills in a finding's display name, web URL and source from
+// the source its repository came from. Findings from a source that isn't
+// configured any more keep their API URL as the name.
+func SetDisplayURL(finding *domain.Finding) {
+	for _, source := range AllSources {
+		if identity, ok := source.Identity(finding.RepoName); ok {
+			finding.RepoDisplayName = identity.Owner + "/" + identity.Name
+			finding.RepoURL = identity.WebURL
+			finding.RepoSource = source.Name()
+			return
+		}
+	}
+	finding.RepoDisplayName = finding.RepoName
+}
+
+// getRepository fetches repoURL into v, turning the responses that mean a
+// repository is gone into ErrRepoUnavailable.
+func getRepository(ctx context.Context, client *github.Client, repoURL string, v any) error {
+	_, err := client.Get(ctx, repoURL, v)
+
+	var statusErr *github.StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusNotFound, http.StatusGone, http.StatusUnavailableForLegalReasons:
+			return fmt.Errorf("%w: %s", ErrRepoUnavailable, statusErr.Status)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("repo lookup failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"openradar/internal/domain"
+)
+
+// httpVerifier makes a single authenticated request and classifies the
+// response. Most providers only need a path and an auth header.
+type httpVerifier struct {
+	provider  string
+	baseURL   string
+	method    string
+	path      string
+	authorize func(req *http.Request, key string)
+	classify  func(status int, body []byte) domain.VerificationStatus
+	metadata  []string // dotted JSON paths copied from a live response
+}
+
+func (v *httpVerifier) Provider() string {
+	return v.provider
+}
+
+func (v *httpVerifier) withBaseURL(baseURL string) *httpVerifier {
+	c := *v
+	c.baseURL = baseURL
+	return &c
+}
+
+func (v *httpVerifier) Verify(ctx context.Context, key string) (Result, error) {
+	method := v.method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(v.baseURL, "/")+v.path, nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create req: %w", err)
+	}
+	v.authorize(req, key)
+
+	res, err := sharedClient.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("http call failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	classify := v.classify
+	if classify == nil {
+		classify = classifyStatus
+	}
+
+	result := Result{Status: classify(res.StatusCode, body)}
+	if result.Status == domain.VerificationLive {
+		result.Metadata = extractMetadata(body, v.metadata)
+	}
+	return result, nil
+}
+
+// classifyStatus is the usual mapping: 2xx is live, 401/403 is dead, and
+// anything else (rate limits, outages) tells us nothing.
+func classifyStatus(status int, body []byte) domain.VerificationStatus {
+	switch {
+	case status >= 200 && status < 300:
+		return domain.VerificationLive
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return domain.VerificationInvalid
+	default:
+		return domain.VerificationUnknown
+	}
+}
+
+func bearer(req *http.Request, key string) {
+	req.Header.Set("Authorization", "Bearer "+key)
+}
+
+func extractMetadata(body []byte, paths []string) domain.VerificationMetadata {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil
+	}
+
+	metadata := make(domain.VerificationMetadata)
+	for _, path := range paths {
+		value := doc
+		for _, part := range strings.Split(path, ".") {
+			object, ok := value.(map[string]any)
+			if !ok {
+				value = nil
+				break
+			}
+			value = object[part]
+		}
+
+		switch value := value.(type) {
+		case nil, map[string]any, []any:
+			continue
+		case string:
+			metadata[path] = value
+		default:
+			metadata[path] = fmt.Sprint(value)
+		}
+	}
+
+	if len(metadata) == 0 {
+		return nil
+	}
+	return metadata
+}
@@ -0,0 +1,136 @@
+package verifier
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"openradar/internal/domain"
+)
+
+func init() {
+	Register(&httpVerifier{
+		provider: "anthropic",
+		baseURL:  "https://api.anthropic.com",
+		path:     "/v1/models?limit=1",
+		authorize: func(req *http.Request, key string) {
+			req.Header.Set("x-api-key", key)
+			req.Header.Set("anthropic-version", "2023-06-01")
+		},
+	})
+
+	Register(&httpVerifier{
+		provider:  "cerebras",
+		baseURL:   "https://api.cerebras.ai",
+		path:      "/v1/models",
+		authorize: bearer,
+	})
+
+	Register(&httpVerifier{
+		provider: "discord",
+		baseURL:  "https://discord.com",
+		path:     "/api/v10/users/@me",
+		authorize: func(req *http.Request, key string) {
+			req.Header.Set("Authorization", "Bot "+key)
+		},
+		metadata: []string{"id", "username"},
+	})
+
+	Register(&httpVerifier{
+		provider: "google",
+		baseURL:  "https://generativelanguage.googleapis.com",
+		path:     "/v1beta/models?pageSize=1",
+		authorize: func(req *http.Request, key string) {
+			// a header rather than ?key=, which ends up in proxy and access logs
+			req.Header.Set("x-goog-api-key", key)
+		},
+		// Google answers a bad key with 400 API_KEY_INVALID, and a good key
+		// without the Generative Language API enabled with 403.
+		classify: func(status int, body []byte) domain.VerificationStatus {
+			switch status {
+			case http.StatusOK:
+				return domain.VerificationLive
+			case http.StatusBadRequest:
+				return domain.VerificationInvalid
+			default:
+				return domain.VerificationUnknown
+			}
+		},
+	})
+
+	Register(&httpVerifier{
+		provider:  "groq",
+		baseURL:   "https://api.groq.com",
+		path:      "/openai/v1/models",
+		authorize: bearer,
+	})
+
+	Register(&httpVerifier{
+		provider:  "mistral",
+		baseURL:   "https://api.mistral.ai",
+		path:      "/v1/models",
+		authorize: bearer,
+	})
+
+	Register(&httpVerifier{
+		provider:  "openrouter",
+		baseURL:   "https://openrouter.ai",
+		path:      "/api/v1/key",
+		authorize: bearer,
+		metadata:  []string{"data.label", "data.usage", "data.limit", "data.is_free_tier"},
+	})
+
+	Register(&httpVerifier{
+		provider:  "slack",
+		baseURL:   "https://slack.com",
+		method:    http.MethodPost,
+		path:      "/api/auth.test",
+		authorize: bearer,
+		// Slack always answers 200, with the result in the body.
+		classify: func(status int, body []byte) domain.VerificationStatus {
+			if status != http.StatusOK {
+				return classifyStatus(status, body)
+			}
+			var res struct {
+				OK    bool   `json:"ok"`
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal(body, &res); err != nil {
+				return domain.VerificationUnknown
+			}
+			switch {
+			case res.OK:
+				return domain.VerificationLive
+			case res.Error == "invalid_auth" || res.Error == "account_inactive" || res.Error == "token_revoked" || res.Error == "token_expired":
+				return domain.VerificationInvalid
+			default:
+				return domain.VerificationUnknown
+			}
+		},
+		metadata: []string{"team", "team_id", "user", "user_id", "url"},
+	})
+
+	Register(&httpVerifier{
+		provider:  "xai",
+		baseURL:   "https://api.x.ai",
+		path:      "/v1/api-key",
+		authorize: bearer,
+		// A blocked or disabled key still answers 200, with flags saying so.
+		classify: func(status int, body []byte) domain.VerificationStatus {
+			if status != http.StatusOK {
+				return classifyStatus(status, body)
+			}
+			var res struct {
+				Blocked  bool `json:"api_key_blocked"`
+				Disabled bool `json:"api_key_disabled"`
+			}
+			if err := json.Unmarshal(body, &res); err != nil {
+				return domain.VerificationUnknown
+			}
+			if res.Blocked || res.Disabled {
+				return domain.VerificationInvalid
+			}
+			return domain.VerificationLive
+		},
+		metadata: []string{"name", "team_id", "user_id"},
+	})
+}
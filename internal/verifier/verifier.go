@@ -0,0 +1,83 @@
+// Live key verification. Each provider gets a Verifier that calls the
+// provider's cheapest authenticated endpoint to tell live keys from dead ones.
+package verifier
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"openradar/internal/domain"
+)
+
+type Result struct {
+	Status   domain.VerificationStatus
+	Metadata domain.VerificationMetadata
+}
+
+type Verifier interface {
+	Provider() string
+	Verify(ctx context.Context, key string) (Result, error)
+}
+
+var (
+	verifiers   = make(map[string]Verifier)
+	verifiersMu sync.RWMutex
+)
+
+// No client timeout: callers bound each verification with VERIFY_TIMEOUT
+// through the context.
+var sharedClient = &http.Client{
+	Transport: &http.Transport{
+		MaxIdleConns:        20,
+		MaxIdleConnsPerHost: 5,
+		IdleConnTimeout:     30 * time.Second,
+	},
+}
+
+func Register(v Verifier) {
+	verifiersMu.Lock()
+	verifiers[v.Provider()] = v
+	verifiersMu.Unlock()
+}
+
+func Get(provider string) (Verifier, bool) {
+	verifiersMu.RLock()
+	defer verifiersMu.RUnlock()
+	v, ok := verifiers[provider]
+	return v, ok
+}
+
+// Configure points verifiers at different base URLs, keyed by provider.
+// Used for proxies and for testing against local stand-ins. Each verifier is
+// replaced by a copy built with the new base URL rather than changed in
+// place, so verifications already running aren't racing the write.
+func Configure(baseURLs map[string]string) {
+	verifiersMu.Lock()
+	defer verifiersMu.Unlock()
+	for provider, baseURL := range baseURLs {
+		if v, ok := verifiers[provider].(*httpVerifier); ok {
+			verifiers[provider] = v.withBaseURL(baseURL)
+		} else {
+			log.Printf("no verifier for provider %s, ignoring base url", provider)
+		}
+	}
+}
+
+// Verify checks key against its provider. Providers without a verifier, and
+// any error talking to the provider, come back as unknown.
+func Verify(ctx context.Context, provider string, key string) Result {
+	v, ok := Get(provider)
+	if !ok {
+		return Result{Status: domain.VerificationUnknown}
+	}
+
+	result, err := v.Verify(ctx, key)
+	if err != nil {
+		log.Printf("failed to verify %s key: %v", provider, err)
+		return Result{Status: domain.VerificationUnknown}
+	}
+	return result
+}
@@ -4,23 +4,30 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime/debug"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"openradar/internal/config"
 	"openradar/internal/db"
 	"openradar/internal/domain"
 	"openradar/internal/queue"
-	"openradar/internal/scanner"
+	"openradar/internal/scanner/history"
 	"openradar/internal/server"
+	"openradar/internal/sources"
+	"openradar/internal/verifier"
 
 	"openradar/internal/scanner/detectors"
+	"openradar/internal/scanner/filters"
 
 	"gorm.io/gorm"
 )
@@ -43,38 +50,206 @@ func hasTargetExt(name string) bool {
 	return ok
 }
 
-func runAllDetectors(src string, fileName string, scanJobID string, url string, DBtoSaveIn *gorm.DB) {
-	for _, scanFunction := range detectors.AllDetectors {
-		key, found, provider := scanFunction(src)
-		if found && detectors.EnsureKeyIsntSpam(key) {
-			log.Printf("Match found: %s\n", key)
-			finding := domain.NewFinding(
-				scanJobID,
-				url,
-				fileName,
-				key,
-				provider,
-			)
-			checkedFinding, err := db.GetFindingByKey(key, DBtoSaveIn)
-			_ = checkedFinding
+// scanTarget is where a file being scanned came from, and where new
+// findings get announced.
+type scanTarget struct {
+	job    *domain.ScanJob // counts files and findings as they're scanned
+	url    string
+	branch string
+	commit history.Commit
+	hub    *server.Hub
+}
 
-			if err != nil {
-				if err := db.AddFinding(finding, DBtoSaveIn); err != nil {
-					log.Printf("Failed to save finding for key %s: %v\n", key, err)
-				}
+// ScanFile scans the contents of one file in job's repository, saving new
+// findings the way a cloned file's are.
+func ScanFile(ctx context.Context, job *domain.ScanJob, fileName string, src string, DBtoSaveIn *gorm.DB, conf config.Config) error {
+	return runAllDetectors(ctx, src, fileName, scanTarget{job: job, url: job.RepositoryURL}, DBtoSaveIn, conf)
+}
+
+func runAllDetectors(ctx context.Context, src string, fileName string, target scanTarget, DBtoSaveIn *gorm.DB, conf config.Config) error {
+	target.job.FileCount++
+	return saveMatches(ctx, detectors.FindAll(src), fileName, target, DBtoSaveIn, conf)
+}
+
+// saveMatches saves every new finding, leaving out keys marked with an
+// AllowComment. A failed save fails the job, so the queue retries it;
+// findings saved before the failure are skipped next time.
+func saveMatches(ctx context.Context, matches []detectors.Match, fileName string, target scanTarget, DBtoSaveIn *gorm.DB, conf config.Config) error {
+	for _, match := range matches {
+		if match.Suppressed != "" {
+			if conf.Scanner.FilterDebug {
+				log.Printf("Skipped %s key %s (%s:%d): suppressed %s", match.Provider, detectors.Redact(match.Key), fileName, match.Line, match.Suppressed)
+			}
+			continue
+		}
+		if rejection, rejected := filters.Check(match); rejected {
+			if conf.Scanner.FilterDebug {
+				log.Printf("Filtered %s key %s (%s:%d): %s, %s", match.Provider, detectors.Redact(match.Key), fileName, match.Line, rejection.Reason, rejection.Detail)
 			}
+			continue
 		}
+
+		log.Printf("Match found: %s (%s:%d:%d)\n", match.Key, fileName, match.Line, match.Column)
+		finding := domain.NewFinding(
+			target.job.ID,
+			target.url,
+			fileName,
+			match.Key,
+			match.Provider,
+			match.Offset,
+			match.Line,
+			match.Column,
+		)
+		finding.Snippet = match.Snippet
+		finding.Confidence = match.Confidence
+		finding.Branch = target.branch
+		finding.CommitSHA = target.commit.SHA
+		finding.AuthorName = target.commit.AuthorName
+		finding.AuthorEmail = target.commit.AuthorEmail
+		if !target.commit.Time.IsZero() {
+			committedAt := target.commit.Time
+			finding.CommittedAt = &committedAt
+		}
+
+		checkedFinding, err := db.GetFindingByKey(match.Key, DBtoSaveIn)
+		_ = checkedFinding
+
+		if err != nil {
+			verifyFinding(ctx, finding, conf)
+			if err := db.AddFinding(finding, DBtoSaveIn); err != nil {
+				return fmt.Errorf("failed to save %s finding in %s: %w", match.Provider, fileName, err)
+			}
+
+			target.job.FindingCount++
+			if target.hub != nil {
+				sources.SetDisplayURL(finding)
+				target.hub.BroadcastFinding(finding)
+			}
+		}
+	}
+	return nil
+}
+
+// verifyFinding checks a new finding against its provider before it's saved.
+func verifyFinding(ctx context.Context, finding *domain.Finding, conf config.Config) {
+	if !conf.Verifier.Enabled {
+		return
+	}
+
+	verifyCtx, cancel := context.WithTimeout(ctx, conf.Verifier.Timeout)
+	defer cancel()
+
+	result := verifier.Verify(verifyCtx, finding.Provider, finding.Key)
+	finding.SetVerification(result.Status, result.Metadata)
+	if result.Status == domain.VerificationLive {
+		finding.LiveChecks = 1
+		finding.ScheduleReverification(conf.Verifier.RecheckBase, conf.Verifier.RecheckMax)
+	}
+	log.Printf("Verified %s key in %s: %s", finding.Provider, finding.RepoName, result.Status)
+}
+
+func cloneRepo(ctx context.Context, cloneURL string, dir string, conf config.Config) error {
+	switch conf.Scanner.HistoryMode {
+	case config.HistoryHead:
+		return gitClone(ctx, cloneURL, dir, "--depth", "1")
+	case config.HistoryCommits:
+		return gitClone(ctx, cloneURL, dir, "--depth", strconv.Itoa(conf.Scanner.HistoryDepth))
+	case config.HistoryDays:
+		err := gitClone(ctx, cloneURL, dir, "--shallow-since", historySince(conf).Format(time.RFC3339))
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		// --shallow-since fails when no commit is that recent. The history
+		// walk skips the latest commit too, since it's older than the window,
+		// so there's nothing to scan, but the clone still has to work.
+		log.Printf("shallow clone since %d days failed (%v), cloning the latest commit instead", conf.Scanner.HistoryDepth, err)
+		return gitClone(ctx, cloneURL, dir, "--depth", "1")
 	}
+	return gitClone(ctx, cloneURL, dir)
 }
 
-func cloneRepo(ctx context.Context, cloneURL string, dir string) error {
-	cmd := exec.CommandContext(ctx, "git", "clone", "--depth", "1", "--single-branch", cloneURL, dir)
+func gitClone(ctx context.Context, cloneURL string, dir string, depth ...string) error {
+	args := append([]string{"clone", "--single-branch", "--no-tags"}, depth...)
+	args = append(args, cloneURL, dir)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 	return cmd.Run()
 }
 
-func scanClonedFiles(dir string, scanJobID string, url string, DBtoSaveIn *gorm.DB, conf config.Config) error {
+// cloneAndScan clones the repo and scans either the checked out files or,
+// in a history mode, every line added by the commits that were fetched.
+func cloneAndScan(ctx context.Context, cloneURL string, dir string, target scanTarget, DBtoSaveIn *gorm.DB, conf config.Config) error {
+	cloneCtx, cloneCancel := context.WithTimeout(ctx, conf.Scanner.CloneTimeout)
+	defer cloneCancel()
+
+	if err := cloneRepo(cloneCtx, cloneURL, dir, conf); err != nil {
+		return fmt.Errorf("failed to clone: %w", err)
+	}
+	target.job.BytesCloned = dirSize(dir)
+
+	if conf.Scanner.HistoryMode != config.HistoryHead {
+		return scanHistory(ctx, dir, target, DBtoSaveIn, conf)
+	}
+
+	head, err := history.Head(ctx, dir)
+	if err != nil {
+		log.Printf("failed to read head commit: %v", err)
+	}
+	target.commit = head
+	return scanClonedFiles(ctx, dir, target, DBtoSaveIn, conf)
+}
+
+// scanHistory scans the lines added by each fetched commit, oldest first, so
+// a key is attributed to the commit that introduced it.
+func scanHistory(ctx context.Context, dir string, target scanTarget, DBtoSaveIn *gorm.DB, conf config.Config) error {
+	opts := history.Options{
+		MaxSize: conf.Scanner.MaxFileSizeKB * 1024,
+		Include: hasTargetExt,
+	}
+	switch conf.Scanner.HistoryMode {
+	case config.HistoryCommits:
+		opts.MaxCommits = conf.Scanner.HistoryDepth
+	case config.HistoryDays:
+		opts.Since = historySince(conf)
+	}
+
+	var saveErr error
+	err := history.Walk(ctx, dir, opts, func(chunk history.Chunk) {
+		if saveErr != nil { // the job will be retried, no point scanning on
+			return
+		}
+		commitTarget := target
+		commitTarget.commit = chunk.Commit
+		target.job.FileCount++
+		saveErr = saveMatches(ctx, chunk.Matches(), chunk.Path, commitTarget, DBtoSaveIn, conf)
+	})
+	if saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+func historySince(conf config.Config) time.Time {
+	return time.Now().AddDate(0, 0, -conf.Scanner.HistoryDepth)
+}
+
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+func scanClonedFiles(ctx context.Context, dir string, target scanTarget, DBtoSaveIn *gorm.DB, conf config.Config) error {
 	var buf bytes.Buffer
 	maxSize := int64(conf.Scanner.MaxFileSizeKB * 1024)
 
@@ -117,81 +292,242 @@ func scanClonedFiles(dir string, scanJobID string, url string, DBtoSaveIn *gorm.
 		}
 
 		relPath, _ := filepath.Rel(dir, path)
-		runAllDetectors(buf.String(), relPath, scanJobID, url, DBtoSaveIn)
-		return nil
+		return runAllDetectors(ctx, buf.String(), relPath, target, DBtoSaveIn, conf)
 	})
 }
 
-func Start(ctx context.Context, conf config.Config, DBtoSaveIn *gorm.DB, Hub *server.Hub) {
+// scanPush scans only the lines changed by a push, using the compare API
+// instead of cloning the repository.
+func scanPush(ctx context.Context, comparer sources.Comparer, job *domain.ScanJob, target scanTarget, DBtoSaveIn *gorm.DB, conf config.Config) error {
+	comparison, err := comparer.Compare(ctx, job.RepositoryURL, job.BeforeSHA, job.HeadSHA)
+	if err != nil {
+		return err
+	}
+	if len(comparison.Files) >= sources.MaxComparisonFiles {
+		return fmt.Errorf("push changed too many files to compare")
+	}
+
+	target.branch = job.Branch
+	target.commit = history.Commit{SHA: job.HeadSHA}
+	for _, commit := range comparison.Commits {
+		if commit.SHA == job.HeadSHA {
+			target.commit.AuthorName = commit.Commit.Author.Name
+			target.commit.AuthorEmail = commit.Commit.Author.Email
+			target.commit.Time = commit.Commit.Author.Date
+		}
+	}
+
+	for _, file := range comparison.Files {
+		if file.Status == "removed" || !hasTargetExt(file.Filename) {
+			continue
+		}
+		if file.Patch == "" { // binary, or too big for GitHub to diff
+			continue
+		}
+
+		chunk := history.FromPatch(target.commit, file.Filename, file.Patch)
+		target.job.FileCount++
+		if err := saveMatches(ctx, chunk.Matches(), chunk.Path, target, DBtoSaveIn, conf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func saveRepository(job *domain.ScanJob, DBtoSaveIn *gorm.DB) {
+	addedRepo := domain.NewRepository(
+		job.ID,
+		job.RepositoryURL,
+	)
+
+	existingRepo, err := db.GetRepositoryByName(job.RepositoryURL, DBtoSaveIn)
+	_ = existingRepo
+
+	if err != nil {
+		if err := db.AddRepository(addedRepo, DBtoSaveIn); err != nil {
+			log.Printf("Failed to save repository: %v", err)
+		}
+	} else {
+		if err := db.UpdateRepository(addedRepo, DBtoSaveIn); err != nil {
+			log.Printf("Failed to save repository: %v", err)
+		}
+	}
+}
+
+func broadcastRepo(Hub *server.Hub, repo sources.Repository) {
+	msg, err := json.Marshal(repo)
+	if err != nil {
+		log.Printf("Failed to send?")
+		return
+	}
+	Hub.Broadcast <- msg
+}
+
+// processJob scans one repository. An error means the job failed; repos that
+// are skipped for being too big are not an error.
+func processJob(ctx context.Context, job *domain.ScanJob, conf config.Config, DBtoSaveIn *gorm.DB, Hub *server.Hub) error {
+	source, ok := sources.For(job.RepositoryURL)
+	if !ok {
+		return queue.Permanent(fmt.Errorf("no source for %s", job.RepositoryURL))
+	}
+
+	repo, err := source.Repository(ctx, job.RepositoryURL)
+	if errors.Is(err, sources.ErrRepoUnavailable) {
+		return queue.Permanent(fmt.Errorf("failed to fetch repo: %w", err))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch repo: %w", err)
+	}
+
+	comparer, canCompare := source.(sources.Comparer)
+	if conf.Scanner.PushDiff && canCompare && job.HeadSHA != "" {
+		target := scanTarget{job: job, url: job.RepositoryURL, hub: Hub}
+		err := scanPush(ctx, comparer, job, target, DBtoSaveIn, conf)
+		if err == nil {
+			broadcastRepo(Hub, repo)
+			saveRepository(job, DBtoSaveIn)
+			log.Printf("Finished processing push %s..%s for %s", job.BeforeSHA, job.HeadSHA, repo.URL)
+			return nil
+		}
+		log.Printf("falling back to a full scan of %s: %v", job.RepositoryURL, err)
+	}
+
+	if repo.Size > uint(conf.Scanner.MaxRepoSizeMB)*1000000 {
+		log.Printf("skipping repo %s because it is too large", job.RepositoryURL)
+		return nil
+	}
+
+	dir, err := os.MkdirTemp("", "openradar-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	broadcastRepo(Hub, repo)
+
+	target := scanTarget{job: job, url: job.RepositoryURL, branch: repo.DefaultBranch, hub: Hub}
+	cloneURL, err := source.CloneURL(ctx, repo)
+	if err != nil {
+		log.Printf("cloning %s anonymously: %v", job.RepositoryURL, err)
+		cloneURL = repo.CloneURL
+	}
+
+	if err := cloneAndScan(ctx, cloneURL, dir, target, DBtoSaveIn, conf); err != nil {
+		return err
+	}
+
+	saveRepository(job, DBtoSaveIn)
+
+	log.Printf("Finished processing scan job %s", repo.URL)
+	return nil
+}
+
+// extendClaim renews the claim on job every interval while it's scanned.
+// If the claim is lost, because it expired and another worker has the job
+// now, the returned context is cancelled to stop the scan. stop ends the
+// renewals and reports whether the claim was lost.
+func extendClaim(ctx context.Context, jobQueue queue.Queue, job *domain.ScanJob, interval time.Duration) (jobCtx context.Context, stop func() bool) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	var lost atomic.Bool
+	quit := make(chan struct{})
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
 		for {
 			select {
-			case <-ctx.Done():
+			case <-quit:
 				return
-			case job := <-queue.JobQueue:
-				log.Printf("Starting to process scan job %s for repository %s", job.ID, job.RepositoryURL)
-
-				job.Status = domain.JobStatusInProgress
-				repo, err := scanner.ScanRepo(context.Background(), job.RepositoryURL, conf.GitHub.Key)
+			case <-jobCtx.Done():
+				return
+			case <-ticker.C:
+				err := jobQueue.Extend(jobCtx, job)
+				if errors.Is(err, queue.ErrClaimLost) {
+					log.Printf("lost the claim on job %s, stopping its scan", job.ID)
+					lost.Store(true)
+					cancel()
+					return
+				}
 				if err != nil {
-					log.Printf("failed to scan repo %s: %v", job.RepositoryURL, err)
-					continue
+					log.Printf("failed to extend claim on job %s: %v", job.ID, err)
 				}
+			}
+		}
+	}()
+	return jobCtx, func() bool {
+		close(quit)
+		<-done
+		cancel()
+		return lost.Load()
+	}
+}
 
-				job.Status = domain.JobStatusCompleted
-				job.UpdatedAt = time.Now()
-
-				if repo.Size <= uint(conf.Scanner.MaxRepoSizeMB)*1000000 {
-					dir, err := os.MkdirTemp("", "openradar-")
-					if err != nil {
-						log.Printf("failed to create temp dir: %v", err)
-						continue
-					}
-
-					msg, err := json.Marshal(repo)
-					if err != nil {
-						log.Printf("Failed to send?")
-					}
-					Hub.Broadcast <- msg
-
-					addedRepo := domain.NewRepository(
-						job.ID,
-						job.RepositoryURL,
-					)
-
-					cloneCtx, cloneCancel := context.WithTimeout(ctx, 60*time.Second)
-					err = cloneRepo(cloneCtx, repo.Clone_Url, dir)
-					cloneCancel()
-					if err != nil {
-						os.RemoveAll(dir)
-						log.Printf("failed to clone repo %s: %v", job.RepositoryURL, err)
-						continue
-					}
-
-					if err := scanClonedFiles(dir, job.ID, job.RepositoryURL, DBtoSaveIn, conf); err != nil {
-						log.Printf("error while scanning files: %v", err)
-					}
-
-					os.RemoveAll(dir)
-
-					existingRepo, err := db.GetRepositoryByName(job.RepositoryURL, DBtoSaveIn)
-					_ = existingRepo
-
-					if err != nil {
-						if err := db.AddRepository(addedRepo, DBtoSaveIn); err != nil {
-							log.Printf("Failed to save repository: %v", err)
-						}
-					} else {
-						if err := db.UpdateRepository(addedRepo, DBtoSaveIn); err != nil {
-							log.Printf("Failed to save repository: %v", err)
-						}
-					}
-				}
+// runJob scans a claimed job and releases it. A job whose claim was lost
+// belongs to another worker now, so it's left for that worker to release
+// and record.
+func runJob(ctx context.Context, job *domain.ScanJob, conf config.Config, DBtoSaveIn *gorm.DB, Hub *server.Hub, jobQueue queue.Queue) {
+	log.Printf("Starting to process scan job %s for repository %s", job.ID, job.RepositoryURL)
+
+	if err := db.SaveScanJob(job, DBtoSaveIn, job.MarkStarted()); err != nil {
+		log.Printf("failed to record start of job %s: %v", job.ID, err)
+	}
+
+	jobCtx, stopExtending := extendClaim(ctx, jobQueue, job, conf.Queue.VisibilityTimeout/3)
+	scanErr := processJob(jobCtx, job, conf, DBtoSaveIn, Hub)
+	if stopExtending() {
+		return
+	}
 
-				debug.FreeOSMemory()
+	if scanErr != nil {
+		log.Printf("failed to scan repo %s: %v", job.RepositoryURL, scanErr)
+		transitions := []domain.ScanJobTransition{job.MarkFinished(domain.JobStatusFailed, scanErr)}
+		err := jobQueue.Fail(ctx, job, scanErr)
+		if errors.Is(err, queue.ErrClaimLost) {
+			log.Printf("lost the claim on job %s before recording its failure", job.ID)
+			return
+		}
+		if err != nil {
+			log.Printf("failed to release job %s: %v", job.ID, err)
+		}
+		if job.Status != domain.JobStatusFailed { // retrying, or dead
+			transitions = append(transitions, job.Transition(domain.JobStatusFailed))
+		}
+		if err := db.SaveScanJob(job, DBtoSaveIn, transitions...); err != nil {
+			log.Printf("failed to record failure of job %s: %v", job.ID, err)
+		}
+		return
+	}
+
+	transition := job.MarkFinished(domain.JobStatusCompleted, nil)
+	err := jobQueue.Complete(ctx, job)
+	if errors.Is(err, queue.ErrClaimLost) {
+		log.Printf("lost the claim on job %s before recording its completion", job.ID)
+		return
+	}
+	if err != nil {
+		log.Printf("failed to release job %s: %v", job.ID, err)
+	}
+	if err := db.SaveScanJob(job, DBtoSaveIn, transition); err != nil {
+		log.Printf("failed to record completion of job %s: %v", job.ID, err)
+	}
+}
 
-				log.Printf("Finished processing scan job %s", repo.Url)
+func Start(ctx context.Context, conf config.Config, DBtoSaveIn *gorm.DB, Hub *server.Hub, jobQueue queue.Queue) {
+	go func() {
+		for {
+			job, err := jobQueue.Dequeue(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Printf("failed to dequeue job: %v", err)
+				time.Sleep(5 * time.Second)
+				continue
 			}
+
+			runJob(ctx, job, conf, DBtoSaveIn, Hub, jobQueue)
+			debug.FreeOSMemory()
 		}
 	}()
 }
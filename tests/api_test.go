@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"openradar/internal/api"
+	"openradar/internal/domain"
+	"openradar/internal/scanner/detectors"
+)
+
+// dryRunDB builds queries without a database to run them against.
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 user=openradar dbname=openradar"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// findingsDB is a dry run database whose findings are rows, recording every
+// finding saved to it.
+func findingsDB(t *testing.T, rows []domain.Finding) (*gorm.DB, *[]domain.Finding) {
+	db := dryRunDB(t).Session(&gorm.Session{SkipDefaultTransaction: true, Logger: logger.Discard})
+	var saved []domain.Finding
+	db.Callback().Query().Replace("gorm:query", func(tx *gorm.DB) {
+		switch dest := tx.Statement.Dest.(type) {
+		case *[]domain.Finding:
+			*dest = slices.Clone(rows)
+		case *domain.Finding: // looking one up; none of the rows match
+			tx.AddError(gorm.ErrRecordNotFound)
+		}
+	})
+	save := func(tx *gorm.DB) {
+		if finding, ok := tx.Statement.Dest.(*domain.Finding); ok {
+			saved = append(saved, *finding)
+		}
+	}
+	db.Callback().Create().Replace("gorm:create", save)
+	db.Callback().Update().Replace("gorm:update", save)
+	return db, &saved
+}
+
+func TestLatestFindingsProviderFilter(t *testing.T) {
+	// as with SCAN_GENERIC=false
+	detectors.Unregister(detectors.Generic.Provider())
+	t.Cleanup(func() { detectors.Register(detectors.Generic) })
+
+	testCases := []struct {
+		name     string
+		provider string
+		minAge   string
+		invalid  bool
+	}{
+		{name: "All providers", provider: "*", minAge: "24h"},
+		{name: "Registered provider", provider: "groq", minAge: "24h"},
+		{name: "Generic while it's off", provider: "generic", minAge: "24h"},
+		{name: "Unknown provider", provider: "nope", minAge: "24h", invalid: true},
+		{name: "Bad min age", provider: "*", minAge: "yesterday", invalid: true},
+	}
+
+	db := dryRunDB(t)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := api.GetLatestFindings(1, 25, tc.provider, tc.minAge, db)
+			if invalid := errors.Is(err, api.ErrInvalidQuery); invalid != tc.invalid {
+				t.Errorf("expected invalid query %v, got %v", tc.invalid, err)
+			}
+		})
+	}
+}
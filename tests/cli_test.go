@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"openradar/internal/cli"
+	"openradar/internal/scanner/detectors"
+)
+
+func TestScanExitCodes(t *testing.T) {
+	// scan turns the generic detector off unless -generic is given
+	t.Cleanup(func() {
+		detectors.Unregister(detectors.Generic.Provider())
+		detectors.Register(detectors.Generic)
+	})
+	const groqKey = "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"
+
+	write := func(t *testing.T, files map[string]string) string {
+		dir := t.TempDir()
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dir
+	}
+
+	testCases := []struct {
+		name     string
+		files    map[string]string
+		args     []string // {{dir}} is the scanned directory
+		stdin    string
+		expected int
+	}{
+		{
+			name:     "Clean",
+			files:    map[string]string{"main.go": "package main\n"},
+			args:     []string{"{{dir}}"},
+			expected: cli.ExitClean,
+		},
+		{
+			name:     "Finding",
+			files:    map[string]string{"config.env": "GROQ=" + groqKey + "\n"},
+			args:     []string{"{{dir}}"},
+			expected: cli.ExitFindings,
+		},
+		{
+			name:     "Flags after the path",
+			files:    map[string]string{"config.env": "GROQ=" + groqKey + "\n"},
+			args:     []string{"{{dir}}", "-format", "json"},
+			expected: cli.ExitFindings,
+		},
+		{
+			name:     "Suppressed inline",
+			files:    map[string]string{"config.env": "GROQ=" + groqKey + " # openradar:allow\n"},
+			args:     []string{"{{dir}}"},
+			expected: cli.ExitClean,
+		},
+		{
+			name:     "Finding on stdin",
+			args:     []string{"-"},
+			stdin:    "GROQ=" + groqKey + "\n",
+			expected: cli.ExitFindings,
+		},
+		{
+			name:     "Unknown flag",
+			args:     []string{"-nope", "{{dir}}"},
+			expected: cli.ExitError,
+		},
+		{
+			name:     "Two paths",
+			args:     []string{"{{dir}}", "{{dir}}"},
+			expected: cli.ExitError,
+		},
+		{
+			name:     "Unknown format",
+			files:    map[string]string{"config.env": "GROQ=" + groqKey + "\n"},
+			args:     []string{"-format", "xml", "{{dir}}"},
+			expected: cli.ExitError,
+		},
+		{
+			name:     "Missing baseline",
+			files:    map[string]string{"config.env": "GROQ=" + groqKey + "\n"},
+			args:     []string{"-baseline", "{{dir}}/missing.json", "{{dir}}"},
+			expected: cli.ExitError,
+		},
+		{
+			name:     "Update baseline without baseline",
+			args:     []string{"-update-baseline", "{{dir}}"},
+			expected: cli.ExitError,
+		},
+		{
+			name:     "Update baseline",
+			files:    map[string]string{"config.env": "GROQ=" + groqKey + "\n"},
+			args:     []string{"-baseline", "{{dir}}/baseline.json", "-update-baseline", "{{dir}}"},
+			expected: cli.ExitClean,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := write(t, tc.files)
+			args := make([]string, len(tc.args))
+			for i, arg := range tc.args {
+				args[i] = strings.ReplaceAll(arg, "{{dir}}", dir)
+			}
+
+			var stdout, stderr bytes.Buffer
+			if code := cli.Scan(args, strings.NewReader(tc.stdin), &stdout, &stderr); code != tc.expected {
+				t.Errorf("expected exit code %d, got %d\n%s", tc.expected, code, stderr.String())
+			}
+		})
+	}
+
+	t.Run("Baseline suppresses recorded findings", func(t *testing.T) {
+		dir := write(t, map[string]string{"config.env": "GROQ=" + groqKey + "\n"})
+		baseline := filepath.Join(t.TempDir(), "baseline.json")
+
+		var out bytes.Buffer
+		if code := cli.Scan([]string{"-baseline", baseline, "-update-baseline", dir}, nil, &out, &out); code != cli.ExitClean {
+			t.Fatalf("failed to record baseline: %d\n%s", code, out.String())
+		}
+		if code := cli.Scan([]string{"-baseline", baseline, dir}, nil, &out, &out); code != cli.ExitClean {
+			t.Errorf("expected the recorded finding to be suppressed, got exit code %d\n%s", code, out.String())
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"openradar/internal/domain"
+	"openradar/internal/github"
+	"openradar/internal/queue"
+	"openradar/internal/scanner"
+	"openradar/internal/sources"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func activity(ids ...int64) []sources.Activity {
+	var activity []sources.Activity
+	for _, id := range ids {
+		activity = append(activity, sources.Activity{ID: id})
+	}
+	return activity
+}
+
+func TestNewActivity(t *testing.T) {
+	tests := []struct {
+		name     string
+		activity []sources.Activity
+		lastID   int64
+		fresh    []int64
+		gap      bool
+	}{
+		{"first poll", activity(103, 102, 101), 0, []int64{101, 102, 103}, false},
+		{"caught up", activity(103, 102, 101), 101, []int64{102, 103}, false},
+		{"nothing new", activity(103, 102, 101), 103, nil, false},
+		{"window overflowed", activity(203, 202, 201), 101, []int64{201, 202, 203}, true},
+		{"empty feed", nil, 101, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fresh, gap := sources.NewActivity(tt.activity, tt.lastID)
+			var ids []int64
+			for _, a := range fresh {
+				ids = append(ids, a.ID)
+			}
+			if !slices.Equal(ids, tt.fresh) || gap != tt.gap {
+				t.Errorf("expected %v gap=%v, got %v gap=%v", tt.fresh, tt.gap, ids, gap)
+			}
+		})
+	}
+}
+
+func TestNextPollInterval(t *testing.T) {
+	tests := []struct {
+		name    string
+		current time.Duration
+		fresh   int
+		gap     bool
+		want    time.Duration
+	}{
+		{"gap", time.Minute, 300, true, sources.MinPollInterval},
+		{"busy", time.Minute, 200, false, 30 * time.Second},
+		{"steady", time.Minute, 100, false, time.Minute},
+		{"quiet", time.Minute, 5, false, 90 * time.Second},
+		{"quiet at max", sources.MaxPollInterval, 0, false, sources.MaxPollInterval},
+		{"busy at min", sources.MinPollInterval, 300, false, sources.MinPollInterval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sources.NextPollInterval(tt.current, tt.fresh, 300, tt.gap); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGitHubClientPagination(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", `<`+srv.URL+`/events?page=2>; rel="next", <`+srv.URL+`/events?page=3>; rel="last"`)
+			w.Write([]byte(`[{"id":"3"}]`))
+			return
+		}
+		w.Header().Set("Link", `<`+srv.URL+`/events?page=1>; rel="first"`)
+		w.Write([]byte(`[{"id":"2"}]`))
+	}))
+	defer srv.Close()
+
+	client := github.NewClient(srv.URL, github.NewPool(github.StaticToken("token")))
+
+	var page []struct{ ID string }
+	res, err := client.Get(context.Background(), srv.URL+"/events", &page)
+	if err != nil || res.NextURL != srv.URL+"/events?page=2" {
+		t.Fatalf("expected a next page, got %+v %v", res, err)
+	}
+	res, err = client.Get(context.Background(), res.NextURL, &page)
+	if err != nil || res.NextURL != "" || page[0].ID != "2" {
+		t.Errorf("expected the last page, got %+v %v %v", res, page, err)
+	}
+}
+
+// scriptedSource returns one poll's activity per call, then nothing, the way
+// a source does once the feed answers 304 Not Modified.
+type scriptedSource struct {
+	name  string
+	polls [][]sources.Activity
+}
+
+func (s *scriptedSource) Name() string {
+	return s.name
+}
+
+func (s *scriptedSource) Poll(ctx context.Context, cursor *domain.EventCursor) ([]sources.Activity, error) {
+	if len(s.polls) == 0 {
+		return nil, nil
+	}
+	activity, _ := sources.NewActivity(s.polls[0], cursor.LastEventID)
+	s.polls = s.polls[1:]
+	return activity, nil
+}
+
+func (s *scriptedSource) Repository(ctx context.Context, repoURL string) (sources.Repository, error) {
+	return sources.Repository{}, sources.ErrRepoUnavailable
+}
+
+func (s *scriptedSource) CloneURL(ctx context.Context, repo sources.Repository) (string, error) {
+	return repo.CloneURL, nil
+}
+
+func (s *scriptedSource) Identity(repoURL string) (sources.Identity, bool) {
+	return sources.Identity{}, false
+}
+
+// brokenQueue fails every Enqueue while broken.
+type brokenQueue struct {
+	queue.Queue
+	broken bool
+}
+
+func (q *brokenQueue) Enqueue(ctx context.Context, job *domain.ScanJob) (bool, error) {
+	if q.broken {
+		return false, errors.New("queue is down")
+	}
+	return q.Queue.Enqueue(ctx, job)
+}
+
+func TestScanJobKeepsUnqueuedActivity(t *testing.T) {
+	saved := sources.AllSources
+	t.Cleanup(func() { sources.AllSources = saved })
+
+	// cursors and recently queued repos outlive the test, so every run gets
+	// its own source
+	name := fmt.Sprintf("scripted-%d", time.Now().UnixNano())
+	repoURL := func(repo string) string {
+		return "https://api.example.com/repos/" + name + "/" + repo
+	}
+	feed := []sources.Activity{
+		{ID: 3, RepoURL: repoURL("three")},
+		{ID: 2, RepoURL: repoURL("two")},
+		{ID: 1, RepoURL: repoURL("one")},
+	}
+	newer := append([]sources.Activity{{ID: 4, RepoURL: repoURL("four")}}, feed...)
+	source := &scriptedSource{name: name, polls: [][]sources.Activity{feed, nil, newer}}
+	sources.AllSources = []sources.Source{source}
+
+	ctx := context.Background()
+	jobQueue := &brokenQueue{Queue: queue.NewInMemoryQueue(10, 0, queue.RetryPolicy{MaxAttempts: 1}), broken: true}
+	DB := dryRunDB(t).Session(&gorm.Session{SkipDefaultTransaction: true, Logger: logger.Discard})
+
+	ids := func(activity []sources.Activity) []int64 {
+		var ids []int64
+		for _, a := range activity {
+			ids = append(ids, a.ID)
+		}
+		return ids
+	}
+
+	if processed, _ := scanner.ScanJob(ctx, jobQueue, DB, false); len(processed) != 0 {
+		t.Fatalf("expected nothing to be queued, got %v", ids(processed))
+	}
+
+	// the feed hasn't changed, so the next poll gets a 304
+	jobQueue.broken = false
+	if processed, _ := scanner.ScanJob(ctx, jobQueue, DB, false); !slices.Equal(ids(processed), []int64{1, 2, 3}) {
+		t.Errorf("expected the activity left from the first poll to be queued, got %v", ids(processed))
+	}
+
+	if processed, _ := scanner.ScanJob(ctx, jobQueue, DB, false); !slices.Equal(ids(processed), []int64{4}) {
+		t.Errorf("expected only the new activity to be queued, got %v", ids(processed))
+	}
+}
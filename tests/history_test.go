@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"openradar/internal/scanner/history"
+)
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Leaky", "GIT_AUTHOR_EMAIL=leaky@example.com",
+		"GIT_COMMITTER_NAME=Leaky", "GIT_COMMITTER_EMAIL=leaky@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
+
+func commitFile(t *testing.T, dir, name, content, message string) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", message)
+	return git(t, dir, "rev-parse", "HEAD")[:40]
+}
+
+func TestHistoryFindsDeletedKeys(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	const groqKey = "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"
+
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	commitFile(t, dir, "app.py", "import os\n\nprint('hi')\n", "initial")
+	leak := commitFile(t, dir, "app.py", "import os\n\nGROQ = \""+groqKey+"\"\nprint('hi')\n", "add key")
+	commitFile(t, dir, "app.py", "import os\n\nGROQ = os.environ['GROQ']\nprint('hi')\n", "remove key")
+
+	type found struct {
+		commit, path, key string
+		line, column      int
+	}
+	var results []found
+	opts := history.Options{Include: func(path string) bool { return filepath.Ext(path) == ".py" }}
+	err := history.Walk(context.Background(), dir, opts, func(chunk history.Chunk) {
+		for _, match := range chunk.Matches() {
+			results = append(results, found{chunk.Commit.SHA, chunk.Path, match.Key, match.Line, match.Column})
+			if chunk.Commit.AuthorEmail != "leaky@example.com" || chunk.Commit.Time.IsZero() {
+				t.Errorf("expected commit author and time, got %+v", chunk.Commit)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("walk failed: %v", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("expected exactly one match, got %+v", results)
+	}
+	expected := found{leak, "app.py", groqKey, 3, 9}
+	if results[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, results[0])
+	}
+
+	results = nil
+	opts.MaxCommits = 1
+	if err := history.Walk(context.Background(), dir, opts, func(chunk history.Chunk) {
+		results = append(results, found{commit: chunk.Commit.SHA})
+	}); err != nil {
+		t.Fatalf("walk failed: %v", err)
+	}
+	if len(results) != 1 || results[0].commit == leak {
+		t.Errorf("expected only the latest commit, got %+v", results)
+	}
+}
+
+func TestHistoryFromPatch(t *testing.T) {
+	patch := "@@ -1,3 +1,4 @@\n import os\n-GROQ = os.environ['GROQ']\n+GROQ = \"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\"\n+DEBUG = True\n print('hi')\n" +
+		"@@ -10,2 +11,3 @@ def main():\n     run()\n+    key = \"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\"\n     done()"
+
+	chunk := history.FromPatch(history.Commit{SHA: "abc"}, "app.py", patch)
+	matches := chunk.Matches()
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %+v", matches)
+	}
+	if matches[0].Line != 2 || matches[0].Column != 9 {
+		t.Errorf("expected first match at 2:9, got %d:%d", matches[0].Line, matches[0].Column)
+	}
+	if matches[1].Line != 12 || matches[1].Column != 12 {
+		t.Errorf("expected second match at 12:12, got %d:%d", matches[1].Line, matches[1].Column)
+	}
+}
@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"openradar/internal/domain"
+	"openradar/internal/queue"
+)
+
+func TestInMemoryQueue(t *testing.T) {
+	ctx := context.Background()
+	var q queue.Queue = queue.NewInMemoryQueue(2, 0, queue.RetryPolicy{MaxAttempts: 1})
+
+	first := domain.NewScanJob("https://api.github.com/repos/user/repo")
+	if added, err := q.Enqueue(ctx, first); !added || err != nil {
+		t.Fatalf("expected job to be added, got %v %v", added, err)
+	}
+
+	duplicate := domain.NewScanJob("https://api.github.com/repos/user/repo")
+	if added, err := q.Enqueue(ctx, duplicate); added || err != nil {
+		t.Errorf("expected duplicate repo to be skipped, got %v %v", added, err)
+	}
+
+	push := domain.NewScanJob("https://api.github.com/repos/user/repo")
+	push.BeforeSHA, push.HeadSHA = "aaa", "bbb"
+	if added, err := q.Enqueue(ctx, push); !added || err != nil {
+		t.Errorf("expected push to be queued separately, got %v %v", added, err)
+	}
+
+	other := domain.NewScanJob("https://api.github.com/repos/user/other")
+	if _, err := q.Enqueue(ctx, other); !errors.Is(err, queue.ErrQueueFull) {
+		t.Errorf("expected queue full, got %v", err)
+	}
+
+	job, err := q.Dequeue(ctx)
+	if err != nil || job.ID != first.ID {
+		t.Fatalf("expected first job, got %+v %v", job, err)
+	}
+
+	// a repo can be queued again once its job has finished
+	if err := q.Complete(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	if added, err := q.Enqueue(ctx, duplicate); !added || err != nil {
+		t.Errorf("expected repo to be queued again, got %v %v", added, err)
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	q.Dequeue(timeout)
+	q.Dequeue(timeout)
+	if _, err := q.Dequeue(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected dequeue on an empty queue to wait for the context, got %v", err)
+	}
+}
+
+func TestScanJobLifecycle(t *testing.T) {
+	job := domain.NewScanJob("https://api.github.com/repos/user/repo")
+
+	started := job.MarkStarted()
+	if started.From != domain.JobStatusPending || started.To != domain.JobStatusInProgress || job.StartedAt == nil {
+		t.Fatalf("unexpected start transition %+v", started)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	failed := job.MarkFinished(domain.JobStatusFailed, errors.New("clone timed out"))
+	if failed.From != domain.JobStatusInProgress || failed.To != domain.JobStatusFailed || failed.Error != "clone timed out" {
+		t.Errorf("unexpected finish transition %+v", failed)
+	}
+	if job.Error != "clone timed out" || job.DurationMS < 2 || job.FinishedAt == nil {
+		t.Errorf("expected error and duration on job, got %+v", job)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := queue.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 500 * time.Millisecond, time.Second},
+		{2, time.Second, 2 * time.Second},
+		{3, 2 * time.Second, 4 * time.Second},
+		{4, 2500 * time.Millisecond, 5 * time.Second}, // capped
+		{10, 2500 * time.Millisecond, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		for range 20 {
+			if delay := policy.Delay(tt.attempt); delay < tt.min || delay > tt.max {
+				t.Errorf("attempt %d: delay %v outside [%v, %v]", tt.attempt, delay, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestInMemoryQueueRetries(t *testing.T) {
+	ctx := context.Background()
+	policy := queue.RetryPolicy{MaxAttempts: 2, BaseDelay: 10 * time.Millisecond, MaxDelay: 10 * time.Millisecond}
+	var q queue.Queue = queue.NewInMemoryQueue(2, 0, policy)
+
+	job := domain.NewScanJob("https://api.github.com/repos/user/repo")
+	q.Enqueue(ctx, job)
+
+	job, _ = q.Dequeue(ctx)
+	if err := q.Fail(ctx, job, errors.New("clone timed out")); err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != domain.JobStatusPending || job.AvailableAt == nil {
+		t.Fatalf("expected job to be retried, got %+v", job)
+	}
+	if added, _ := q.Enqueue(ctx, domain.NewScanJob(job.RepositoryURL)); added {
+		t.Error("expected repo to stay queued while its job backs off")
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	job, err := q.Dequeue(timeout)
+	if err != nil || job.Attempts != 2 {
+		t.Fatalf("expected second attempt, got %+v %v", job, err)
+	}
+
+	q.Fail(ctx, job, errors.New("clone timed out"))
+	if job.Status != domain.JobStatusDead {
+		t.Fatalf("expected job to be dead after its last attempt, got %s", job.Status)
+	}
+	if ids, _ := q.DeadJobIDs(ctx); len(ids) != 1 || ids[0] != job.ID {
+		t.Errorf("expected dead job to be listed, got %v", ids)
+	}
+
+	requeued, err := q.Requeue(ctx, job.ID)
+	if err != nil || requeued.Status != domain.JobStatusPending || requeued.Attempts != 0 {
+		t.Fatalf("expected job to be requeued, got %+v %v", requeued, err)
+	}
+	if _, err := q.Requeue(ctx, job.ID); !errors.Is(err, queue.ErrJobNotFound) {
+		t.Errorf("expected requeued job to no longer be dead, got %v", err)
+	}
+
+	job, _ = q.Dequeue(ctx)
+	q.Fail(ctx, job, queue.Permanent(errors.New("repository unavailable")))
+	if job.Status != domain.JobStatusFailed || job.AvailableAt != nil {
+		t.Errorf("expected permanent failure not to be retried, got %+v", job)
+	}
+}
+
+func TestExpiredClaimRace(t *testing.T) {
+	ctx := context.Background()
+	const visibilityTimeout = 20 * time.Millisecond
+	var q queue.Queue = queue.NewInMemoryQueue(2, visibilityTimeout, queue.RetryPolicy{MaxAttempts: 2})
+
+	q.Enqueue(ctx, domain.NewScanJob("https://api.github.com/repos/user/repo"))
+
+	// the first worker's scan outlives its claim without extending it...
+	first, err := q.Dequeue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * visibilityTimeout)
+
+	// ...so the second worker gets the job
+	timeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	second, err := q.Dequeue(timeout)
+	if err != nil || second.ID != first.ID || second.Attempts != 2 {
+		t.Fatalf("expected the expired job to be handed out again, got %+v %v", second, err)
+	}
+
+	// the first worker can no longer touch it
+	if err := q.Extend(ctx, first); !errors.Is(err, queue.ErrClaimLost) {
+		t.Errorf("expected extending a lost claim to fail, got %v", err)
+	}
+	if err := q.Complete(ctx, first); !errors.Is(err, queue.ErrClaimLost) {
+		t.Errorf("expected completing a lost claim to fail, got %v", err)
+	}
+	if err := q.Fail(ctx, first, errors.New("clone timed out")); !errors.Is(err, queue.ErrClaimLost) {
+		t.Errorf("expected failing a lost claim to fail, got %v", err)
+	}
+
+	// while the second one still owns it
+	if err := q.Extend(ctx, second); err != nil {
+		t.Errorf("expected the current claim to be extended, got %v", err)
+	}
+	if err := q.Complete(ctx, second); err != nil {
+		t.Errorf("expected the current claim to complete, got %v", err)
+	}
+	if added, _ := q.Enqueue(ctx, domain.NewScanJob(second.RepositoryURL)); !added {
+		t.Error("expected the repo to be queued again once the job completed")
+	}
+}
+
+func TestExpiredClaimOnLastAttempt(t *testing.T) {
+	ctx := context.Background()
+	const visibilityTimeout = 20 * time.Millisecond
+	var q queue.Queue = queue.NewInMemoryQueue(2, visibilityTimeout, queue.RetryPolicy{MaxAttempts: 1})
+
+	q.Enqueue(ctx, domain.NewScanJob("https://api.github.com/repos/user/repo"))
+	job, _ := q.Dequeue(ctx)
+	time.Sleep(2 * visibilityTimeout)
+
+	timeout, cancel := context.WithTimeout(ctx, 2*visibilityTimeout)
+	defer cancel()
+	if _, err := q.Dequeue(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a job out of attempts not to be handed out again, got %v", err)
+	}
+	if ids, _ := q.DeadJobIDs(ctx); len(ids) != 1 || ids[0] != job.ID {
+		t.Errorf("expected the job to be dead, got %v", ids)
+	}
+}
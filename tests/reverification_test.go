@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"openradar/internal/config"
+	"openradar/internal/db/cache"
+	"openradar/internal/domain"
+	"openradar/internal/github"
+	"openradar/internal/jobs"
+	"openradar/internal/sources"
+	"openradar/internal/verifier"
+)
+
+func TestReverificationBackoff(t *testing.T) {
+	testCases := []struct {
+		liveChecks int
+		expected   time.Duration
+	}{
+		{liveChecks: 0, expected: time.Hour},
+		{liveChecks: 1, expected: time.Hour},
+		{liveChecks: 2, expected: 2 * time.Hour},
+		{liveChecks: 4, expected: 8 * time.Hour},
+		{liveChecks: 50, expected: 24 * time.Hour},
+	}
+
+	for _, tc := range testCases {
+		finding := &domain.Finding{LiveChecks: tc.liveChecks}
+		before := time.Now()
+		finding.ScheduleReverification(time.Hour, 24*time.Hour)
+
+		got := finding.NextVerificationAt.Sub(before)
+		if got < tc.expected || got > tc.expected+time.Second {
+			t.Errorf("after %d live checks: expected next check in %s, got %s", tc.liveChecks, tc.expected, got)
+		}
+	}
+}
+
+// stubVerifier answers with the status each key is mapped to.
+type stubVerifier struct {
+	provider string
+	statuses map[string]domain.VerificationStatus
+}
+
+func (v stubVerifier) Provider() string {
+	return v.provider
+}
+
+func (v stubVerifier) Verify(ctx context.Context, key string) (verifier.Result, error) {
+	return verifier.Result{Status: v.statuses[key]}, nil
+}
+
+func runJob(t *testing.T, name string, jobContext jobs.JobContext) {
+	for _, job := range jobs.AllJobs {
+		if job.Name == name {
+			job.Func(jobContext)
+			return
+		}
+	}
+	t.Fatalf("no job named %q", name)
+}
+
+func TestReverifyFindings(t *testing.T) {
+	original, _ := verifier.Get("groq")
+	t.Cleanup(func() { verifier.Register(original) })
+	verifier.Register(stubVerifier{provider: "groq", statuses: map[string]domain.VerificationStatus{
+		"still-live": domain.VerificationLive,
+		"revoked":    domain.VerificationInvalid,
+		"outage":     domain.VerificationUnknown,
+	}})
+
+	detectedAt := time.Now().Add(-48 * time.Hour)
+	lastVerified := time.Now().Add(-time.Hour)
+	live := func(key string) domain.Finding {
+		return domain.Finding{
+			ID:                   key,
+			Key:                  key,
+			Provider:             "groq",
+			DetectedAt:           detectedAt,
+			VerifiedStatus:       domain.VerificationLive,
+			VerifiedAt:           &lastVerified,
+			VerificationMetadata: domain.VerificationMetadata{"team": "Acme"},
+			NextVerificationAt:   &lastVerified,
+			LiveChecks:           2,
+		}
+	}
+	DB, saved := findingsDB(t, []domain.Finding{live("still-live"), live("revoked"), live("outage")})
+
+	cfg := config.Config{}
+	cfg.Verifier.Enabled = true
+	cfg.Verifier.Timeout = time.Second
+	cfg.Verifier.RecheckBase = time.Hour
+	cfg.Verifier.RecheckMax = 24 * time.Hour
+
+	before := time.Now()
+	runJob(t, "Re-verify live findings", jobs.JobContext{DB: DB, Cfg: cfg, Ctx: context.Background()})
+
+	if len(*saved) != 3 {
+		t.Fatalf("expected every finding to be saved, got %d", len(*saved))
+	}
+	for _, finding := range *saved {
+		switch finding.Key {
+		case "still-live":
+			if finding.VerifiedStatus != domain.VerificationLive || finding.RevokedAt != nil || finding.LiveChecks != 3 {
+				t.Errorf("expected the key to stay live, got %+v", finding)
+			}
+			// the third live check backs off to 4 hours
+			if finding.NextVerificationAt == nil || finding.NextVerificationAt.Sub(before) < 4*time.Hour {
+				t.Errorf("expected the next check to back off, got %v", finding.NextVerificationAt)
+			}
+		case "revoked":
+			if finding.VerifiedStatus != domain.VerificationInvalid || finding.NextVerificationAt != nil {
+				t.Errorf("expected the key to be revoked and not checked again, got %+v", finding)
+			}
+			if finding.RevokedAt == nil || finding.RevokedAt.Before(before) || finding.RevokedAt != finding.VerifiedAt {
+				t.Errorf("expected the key to be revoked when it was checked, got revoked at %v, verified at %v", finding.RevokedAt, finding.VerifiedAt)
+			}
+			if finding.VerificationMetadata["team"] != "Acme" {
+				t.Errorf("expected the metadata from when it was live to be kept, got %v", finding.VerificationMetadata)
+			}
+		case "outage":
+			if finding.VerifiedStatus != domain.VerificationLive || finding.RevokedAt != nil || finding.LiveChecks != 2 {
+				t.Errorf("expected a failed check to leave the key live, got %+v", finding)
+			}
+			if finding.NextVerificationAt == nil || !finding.NextVerificationAt.After(before) {
+				t.Errorf("expected the check to be retried later, got %v", finding.NextVerificationAt)
+			}
+		}
+	}
+}
+
+func TestRevocationMetrics(t *testing.T) {
+	saved := sources.AllSources
+	t.Cleanup(func() { sources.AllSources = saved })
+	sources.AllSources = []sources.Source{sources.NewGitHub(github.NewClient(github.DefaultBaseURL, github.NewPool()), "https://github.com", "")}
+
+	detectedAt := time.Now().Add(-72 * time.Hour)
+	revokedAfter := func(d time.Duration) *time.Time {
+		at := detectedAt.Add(d)
+		return &at
+	}
+	DB, _ := findingsDB(t, []domain.Finding{
+		{RepoName: "https://api.github.com/repos/alice/one", Provider: "groq", DetectedAt: detectedAt, RevokedAt: revokedAfter(time.Hour)},
+		{RepoName: "https://api.github.com/repos/alice/two", Provider: "groq", DetectedAt: detectedAt, RevokedAt: revokedAfter(3 * time.Hour)},
+		{RepoName: "https://api.github.com/repos/alice/two", Provider: "openai", DetectedAt: detectedAt, RevokedAt: revokedAfter(8 * time.Hour)},
+		{RepoName: "https://api.github.com/repos/bob/one", Provider: "groq", DetectedAt: detectedAt, RevokedAt: revokedAfter(2 * time.Hour)},
+		{RepoName: "https://api.github.com/repos/bob/one", Provider: "openai", DetectedAt: detectedAt, VerifiedStatus: domain.VerificationLive},
+	})
+
+	runJob(t, "Cache time-to-revoke metrics", jobs.JobContext{DB: DB, Ctx: context.Background()})
+
+	metrics := cache.GetCachedRevocationMetrics()
+
+	hours := func(h float64) float64 { return h * 3600 }
+	expectedProviders := []domain.TimeToRevoke{
+		{Name: "groq", Revoked: 3, MeanSeconds: hours(2), MedianSeconds: hours(2)},
+		{Name: "openai", Revoked: 1, StillLive: 1, MeanSeconds: hours(8), MedianSeconds: hours(8)},
+	}
+	expectedOwners := []domain.TimeToRevoke{
+		{Name: "alice", Revoked: 3, MeanSeconds: hours(4), MedianSeconds: hours(3)},
+		{Name: "bob", Revoked: 1, StillLive: 1, MeanSeconds: hours(2), MedianSeconds: hours(2)},
+	}
+	if !reflect.DeepEqual(metrics.ByProvider, expectedProviders) {
+		t.Errorf("expected by provider %+v, got %+v", expectedProviders, metrics.ByProvider)
+	}
+	if !reflect.DeepEqual(metrics.ByOwner, expectedOwners) {
+		t.Errorf("expected by owner %+v, got %+v", expectedOwners, metrics.ByOwner)
+	}
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"openradar/internal/domain"
+	"openradar/internal/verifier"
+)
+
+func TestVerifiers(t *testing.T) {
+	const liveKey = "live-key"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		live := r.Header.Get("Authorization") == "Bearer "+liveKey
+		switch r.URL.Path {
+		case "/openai/v1/models": // groq
+			if !live {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"data":[]}`))
+		case "/api/v1/key": // openrouter
+			if !live {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"data":{"label":"sk-or-v1-abc...","usage":1.5,"limit":null}}`))
+		case "/v1beta/models": // google
+			if r.Header.Get("x-goog-api-key") != liveKey || r.URL.Query().Has("key") {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"models":[]}`))
+		case "/api/auth.test": // slack
+			if !live {
+				w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+				return
+			}
+			w.Write([]byte(`{"ok":true,"team":"Acme","user":"bot"}`))
+		case "/v1/api-key": // xai
+			switch r.Header.Get("Authorization") {
+			case "Bearer " + liveKey:
+				w.Write([]byte(`{"name":"ci","api_key_blocked":false,"api_key_disabled":false}`))
+			case "Bearer blocked-key":
+				w.Write([]byte(`{"name":"ci","api_key_blocked":true,"api_key_disabled":false}`))
+			case "Bearer disabled-key":
+				w.Write([]byte(`{"name":"ci","api_key_blocked":false,"api_key_disabled":true}`))
+			default:
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	baseURLs := map[string]string{
+		"google":     srv.URL,
+		"groq":       srv.URL,
+		"openrouter": srv.URL,
+		"slack":      srv.URL,
+		"xai":        srv.URL,
+		"mistral":    srv.URL + "/broken",
+	}
+	for provider := range baseURLs {
+		original, _ := verifier.Get(provider)
+		t.Cleanup(func() { verifier.Register(original) })
+	}
+	verifier.Configure(baseURLs)
+
+	testCases := []struct {
+		name             string
+		provider         string
+		key              string
+		expectedStatus   domain.VerificationStatus
+		expectedMetadata domain.VerificationMetadata
+	}{
+		{name: "Google live", provider: "google", key: liveKey, expectedStatus: domain.VerificationLive},
+		{name: "Google invalid", provider: "google", key: "dead-key", expectedStatus: domain.VerificationInvalid},
+		{name: "Groq live", provider: "groq", key: liveKey, expectedStatus: domain.VerificationLive},
+		{name: "Groq revoked", provider: "groq", key: "dead-key", expectedStatus: domain.VerificationInvalid},
+		{
+			name:             "OpenRouter metadata",
+			provider:         "openrouter",
+			key:              liveKey,
+			expectedStatus:   domain.VerificationLive,
+			expectedMetadata: domain.VerificationMetadata{"data.label": "sk-or-v1-abc...", "data.usage": "1.5"},
+		},
+		{
+			name:             "Slack live",
+			provider:         "slack",
+			key:              liveKey,
+			expectedStatus:   domain.VerificationLive,
+			expectedMetadata: domain.VerificationMetadata{"team": "Acme", "user": "bot"},
+		},
+		{name: "Slack revoked", provider: "slack", key: "dead-key", expectedStatus: domain.VerificationInvalid},
+		{
+			name:             "xAI live",
+			provider:         "xai",
+			key:              liveKey,
+			expectedStatus:   domain.VerificationLive,
+			expectedMetadata: domain.VerificationMetadata{"name": "ci"},
+		},
+		{name: "xAI blocked", provider: "xai", key: "blocked-key", expectedStatus: domain.VerificationInvalid},
+		{name: "xAI disabled", provider: "xai", key: "disabled-key", expectedStatus: domain.VerificationInvalid},
+		{name: "xAI revoked", provider: "xai", key: "dead-key", expectedStatus: domain.VerificationInvalid},
+		{name: "Provider outage", provider: "mistral", key: liveKey, expectedStatus: domain.VerificationUnknown},
+		{name: "No verifier", provider: "nope", key: liveKey, expectedStatus: domain.VerificationUnknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := verifier.Verify(context.Background(), tc.provider, tc.key)
+			if result.Status != tc.expectedStatus {
+				t.Errorf("expected status %s, got %s", tc.expectedStatus, result.Status)
+			}
+			if len(result.Metadata) != len(tc.expectedMetadata) {
+				t.Errorf("expected metadata %v, got %v", tc.expectedMetadata, result.Metadata)
+			}
+			for k, v := range tc.expectedMetadata {
+				if result.Metadata[k] != v {
+					t.Errorf("expected metadata %s=%s, got %s", k, v, result.Metadata[k])
+				}
+			}
+		})
+	}
+}
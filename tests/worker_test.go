@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"openradar/internal/config"
+	"openradar/internal/domain"
+	"openradar/internal/worker"
+)
+
+func TestScanFileSkipsSuppressedKeys(t *testing.T) {
+	const groqKey = "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"
+	const otherKey = "gsk_Q8vRkW2nTz7LpXcY4mHs9dJf3bGa6eUyWGdyb3FYr1Ko5tNqPz"
+
+	tests := []struct {
+		name     string
+		src      string
+		expected []string
+	}{
+		{"not suppressed", "GROQ=" + groqKey + "\n", []string{groqKey}},
+		{"suppressed", "GROQ=" + groqKey + " # openradar:allow\n", nil},
+		{"suppressed on another line", "GROQ=" + groqKey + " # openradar:allow\nOTHER=" + otherKey + "\n", []string{otherKey}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, saved := findingsDB(t, nil)
+			job := domain.NewScanJob("https://api.github.com/repos/user/repo")
+			if err := worker.ScanFile(context.Background(), job, "config.env", tt.src, db, config.Config{}); err != nil {
+				t.Fatal(err)
+			}
+			var keys []string
+			for _, finding := range *saved {
+				keys = append(keys, finding.Key)
+			}
+			if !reflect.DeepEqual(keys, tt.expected) || job.FindingCount != len(tt.expected) {
+				t.Errorf("expected %v to be saved, got %v (%d counted)", tt.expected, keys, job.FindingCount)
+			}
+		})
+	}
+}